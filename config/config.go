@@ -17,6 +17,10 @@ type (
 		Redis `yaml:"redis"`
 		Gmail `yaml:"gmail"`
 		MinIO `yaml:"minio"`
+		TOTP  `yaml:"totp"`
+		OIDC  `yaml:"oidc"`
+		Geo   `yaml:"geo"`
+		Moderation `yaml:"moderation"`
 	}
 
 	// App -.
@@ -43,7 +47,12 @@ type (
 
 	// JWT -.
 	JWT struct {
-		Secret string `env-required:"true" yaml:"secret" env:"JWT_SECRET"`
+		Secret    string `env-required:"true" yaml:"secret" env:"JWT_SECRET"`
+		KeysDir   string `yaml:"keys_dir" env:"JWT_KEYS_DIR"`     // directory with <kid>.pem / <kid>.pub.pem keys, empty for HS256 only
+		ActiveKid string `yaml:"active_kid" env:"JWT_ACTIVE_KID"` // kid new tokens are signed with
+		// RFC3339 time until which HS256 tokens are still accepted once ActiveKid is set,
+		// empty rejects them right away
+		HS256Until string `yaml:"hs256_until" env:"JWT_HS256_UNTIL"`
 	}
 
 	// Redis -.
@@ -64,6 +73,31 @@ type (
 		MinIOSecredKey string `env-required:"true" yaml:"miniosecredkey" env:"MINIOSECREDKEY"`
 		MinIOBucketName string `env-required:"true" yaml:"minibucketname" env:"MINIOBUCKETNAME"`
 	}
+
+	// TOTP -.
+	TOTP struct {
+		TotpIssuer      string   `yaml:"issuer" env:"TOTP_ISSUER" env-default:"Yalp"`
+		TotpRequiredFor []string `yaml:"required_for" env:"TOTP_REQUIRED_FOR" env-separator:","` // user types that must use two-factor login
+	}
+
+	// OIDC -. Social login is disabled while OidcIssuer is empty.
+	OIDC struct {
+		OidcIssuer       string   `yaml:"issuer" env:"OIDC_ISSUER"`
+		OidcClientID     string   `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+		OidcClientSecret string   `env:"OIDC_CLIENT_SECRET"`
+		OidcRedirectURL  string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"` // must point to /v1/auth/oidc/callback
+		OidcScopes       []string `yaml:"scopes" env:"OIDC_SCOPES" env-separator:"," env-default:"openid,email,profile"`
+	}
+
+	// Geo -. Businesses are not geocoded while GeoGazetteer is empty.
+	Geo struct {
+		GeoGazetteer string `yaml:"gazetteer" env:"GEO_GAZETTEER"` // csv file of place names and coordinates, see pkg/geo
+	}
+
+	// Moderation -. Reviews with banned words are held for an admin, see pkg/moderation.
+	Moderation struct {
+		ModerationBannedWords []string `yaml:"banned_words" env:"MODERATION_BANNED_WORDS" env-separator:","`
+	}
 )
 
 // NewConfig returns app config.
@@ -17,8 +17,48 @@ var (
 	ErrorConflict       = "CONFLICT"
 	ErrorBadRequest     = "BAD_REQUEST"
 	ErrorDuplicateKey   = "DUPLICATE_KEY"
+	ErrorInvalidOtp     = "INVALID_OTP"
+	ErrorOtpExpired     = "OTP_EXPIRED"
+	ErrorTooManyRequest = "TOO_MANY_REQUESTS"
+	ErrorUserBlocked    = "USER_BLOCKED"
+	ErrorNotVerified    = "USER_NOT_VERIFIED"
+	ErrorAccountLocked  = "ACCOUNT_LOCKED"
 )
 
 var (
-	TokenExpireTime = 24 * time.Hour * 7 // 7 days
+	TokenExpireTime        = 24 * time.Hour * 7 // 7 days, lifetime of a session and its refresh tokens
+	AccessTokenExpireTime  = 15 * time.Minute   // 15 minutes
+	SessionActivityPeriod  = time.Minute        // how often a session's last_active_at is written at most
+	SessionCacheExpireTime = 10 * time.Minute   // how long a session and its user's status stay cached in redis
+
+	OtpExpireTime     = 5 * time.Minute // lifetime of an emailed code
+	OtpResendCooldown = time.Minute     // minimum delay between two codes for the same email
+	OtpMaxAttempts    = 5               // wrong guesses before the code is thrown away
+
+	OidcStateExpireTime = 10 * time.Minute // time the user has to finish logging in at the provider
+
+	LoginMaxFailures   = 5                // wrong passwords in a row before the account is locked
+	LoginFailureWindow = 15 * time.Minute // failures are forgotten after this long without another one
+	LoginLockoutTime   = 15 * time.Minute // how long a locked account stays locked
+
+	PolicyReloadPeriod = 10 * time.Second // how long other instances may keep enforcing a changed policy
+
+	BusinessInvitationExpireTime = 7 * 24 * time.Hour // time an invited user has to accept
+	BusinessDefaultTimezone      = "Asia/Tashkent"    // timezone of the opening hours of a business that didn't set one
+	BusinessMaxCategories        = 5                  // categories a single business may be listed under
+	BusinessRatingPriorWeight    = 10                 // reviews worth of the average rating added to every business when sorting by rating
+	BusinessSearchMaxLength      = 200                // characters in the search words of a business search
+	BusinessMaxPriceLevel        = 4                  // price levels go from 1, cheap, to this, expensive
+	BusinessCategoryFacetLimit   = 20                 // categories with the most businesses shown as facets
+
+	BusinessRatingFacets   = []float64{4.5, 4, 3.5, 3}           // minimum ratings counted as facets
+	BusinessDistanceFacets = []float64{1000, 5000, 10000, 25000} // meters around the searched point counted as facets
+
+	PhotoMaxSize int64 = 10 << 20 // 10 MB, largest photo that can be uploaded
+
+	ReviewReplyMaxLength    = 2000 // characters in the reply of a business to a review
+	ReviewDuplicateLookback = 50   // newest other reviews of the author a new review must not repeat
+
+	SearchSuggestCacheExpireTime = time.Minute // how long the suggestions for a typed prefix stay cached in redis
+	SearchSuggestMaxLimit        = 20          // suggestions a single request may ask for
 )
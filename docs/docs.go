@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/admin/categories": {
             "post": {
-                "description": "Login",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The slug is lower case words joined by dashes, names are keyed by two letter language codes and need at least \"en\". Leave parent_id empty for a top level category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,17 +30,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login",
+                "summary": "Create a category",
                 "parameters": [
                     {
-                        "description": "User",
+                        "description": "Category",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.LoginRequest"
+                            "$ref": "#/definitions/entity.Category"
                         }
                     }
                 ],
@@ -43,7 +48,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
+                            "$ref": "#/definitions/entity.Category"
                         }
                     },
                     "400": {
@@ -55,14 +60,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
-            "post": {
+        "/admin/categories/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Logout",
+                "description": "Replaces the slug, parent and names of a category. Moving a category moves everything below it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -70,14 +75,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Update a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Category"
+                        }
+                    }
                 ],
-                "summary": "Logout",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
+                            "$ref": "#/definitions/entity.Category"
                         }
                     },
                     "400": {
@@ -85,13 +108,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Register",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only categories without businesses and subcategories can be deleted",
                 "consumes": [
                     "application/json"
                 ],
@@ -99,25 +131,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register",
+                "summary": "Delete a category",
                 "parameters": [
                     {
-                        "description": "User",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -125,13 +155,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/verify-email": {
-            "post": {
-                "description": "Register",
+        "/admin/reviews/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the pending reviews oldest first, with the flags the pre-screen gave them",
                 "consumes": [
                     "application/json"
                 ],
@@ -139,25 +180,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register",
+                "summary": "Get the review moderation queue",
                 "parameters": [
                     {
-                        "description": "User",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.VerifyEmail"
-                        }
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.ReviewList"
                         }
                     },
                     "400": {
@@ -169,14 +215,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/business": {
-            "put": {
+        "/admin/reviews/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a business",
+                "description": "Publishes a pending or rejected review and notifies its author",
                 "consumes": [
                     "application/json"
                 ],
@@ -184,17 +230,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "admin"
                 ],
-                "summary": "Update a business",
+                "summary": "Approve a review",
                 "parameters": [
                     {
-                        "description": "Business object",
-                        "name": "business",
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note",
+                        "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.ReviewModerationRequest"
                         }
                     }
                 ],
@@ -202,7 +254,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.Review"
                         }
                     },
                     "400": {
@@ -210,16 +262,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/admin/reviews/{id}/reject": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new business entity",
+                "description": "Takes a review off the business and notifies its author with the note as the reason",
                 "consumes": [
                     "application/json"
                 ],
@@ -227,17 +287,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "admin"
                 ],
-                "summary": "Create a new business",
+                "summary": "Reject a review",
                 "parameters": [
                     {
-                        "description": "Business data",
-                        "name": "business",
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the author",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.ReviewModerationRequest"
                         }
                     }
                 ],
@@ -245,7 +312,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.Review"
                         }
                     },
                     "400": {
@@ -254,8 +321,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -263,14 +330,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/business/list": {
+        "/admin/users/{id}/login-events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of businesses",
+                "description": "Lists successful and failed logins to a user's account, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -278,10 +345,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "admin"
                 ],
-                "summary": "Get a list of businesses",
+                "summary": "Get a user's login history",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "number",
                         "description": "page",
@@ -295,19 +369,13 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "owner_id",
-                        "name": "owner_id",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.BusinessList"
+                            "$ref": "#/definitions/entity.LoginEventList"
                         }
                     },
                     "400": {
@@ -319,14 +387,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/business/{id}": {
-            "get": {
+        "/admin/users/{id}/status": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a business by ID",
+                "description": "Changes the user's status. Blocking logs the user out of every session immediately. The user is notified about the change.",
                 "consumes": [
                     "application/json"
                 ],
@@ -334,23 +402,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "admin"
                 ],
-                "summary": "Get a business by ID",
+                "summary": "Block or unblock a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Business ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Status (active or blocked) and reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserStatusChange"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.UserStatusChange"
                         }
                     },
                     "400": {
@@ -358,16 +435,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/users/{id}/status-history": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a business",
+                "description": "Lists the status changes of a user with reasons, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -375,23 +460,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "admin"
                 ],
-                "summary": "Delete a business",
+                "summary": "Get a user's status history",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Business ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
+                            "$ref": "#/definitions/entity.UserStatusHistory"
                         }
                     },
                     "400": {
@@ -403,45 +502,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/business/{id}/image": {
+        "/auth/2fa/disable": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload an image for a specific business",
+                "description": "Disables two-factor authentication after checking a code or a recovery code",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "business"
+                    "auth"
                 ],
-                "summary": "Set an image for a business",
+                "summary": "Disable two-factor authentication",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Business ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image file to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.TwoFactorDisableRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Business"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -450,8 +544,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -459,14 +553,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/event": {
-            "put": {
+        "/auth/2fa/enable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an event",
+                "description": "Confirms setup with a code from the authenticator app and returns one-time recovery codes. They are shown only once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -474,17 +568,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Update an event",
+                "summary": "Enable two-factor authentication",
                 "parameters": [
                     {
-                        "description": "Event object",
-                        "name": "event",
+                        "description": "Code",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Event"
+                            "$ref": "#/definitions/entity.TwoFactorEnableRequest"
                         }
                     }
                 ],
@@ -492,7 +586,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Event"
+                            "$ref": "#/definitions/entity.TwoFactorEnableResponse"
                         }
                     },
                     "400": {
@@ -500,16 +594,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/setup": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create an event",
+                "description": "Generates a new TOTP secret for the caller. Either authenticate with a bearer token or pass the challenge token returned by login when setup is required.",
                 "consumes": [
                     "application/json"
                 ],
@@ -517,17 +619,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Create an event",
+                "summary": "Start two-factor setup",
                 "parameters": [
                     {
-                        "description": "Event object",
-                        "name": "event",
+                        "description": "Challenge token",
+                        "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Event"
+                            "$ref": "#/definitions/entity.TwoFactorSetupRequest"
                         }
                     }
                 ],
@@ -535,7 +636,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Event"
+                            "$ref": "#/definitions/entity.TwoFactorSetupResponse"
                         }
                     },
                     "400": {
@@ -543,18 +644,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/event/:id/participants": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get participants of an event",
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Second step of login. Exchanges the challenge token and a TOTP or recovery code for a session.",
                 "consumes": [
                     "application/json"
                 ],
@@ -562,37 +664,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Get participants of an event",
+                "summary": "Complete two-factor login",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "Page",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Event ID",
-                        "name": "event_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Challenge",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.TwoFactorVerifyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.EventParticipantList"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -600,18 +690,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/event/add-participant": {
+        "/auth/forgot-password": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Add a participant to an event",
+                "description": "Sends a one-time password reset code to the user's email",
                 "consumes": [
                     "application/json"
                 ],
@@ -619,17 +710,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Add a participant to an event",
+                "summary": "Forgot password",
                 "parameters": [
                     {
-                        "description": "Participant object",
-                        "name": "participant",
+                        "description": "Email",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.EventParticipant"
+                            "$ref": "#/definitions/entity.ForgotPasswordRequest"
                         }
                     }
                 ],
@@ -637,7 +728,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.EventParticipant"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -649,14 +740,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/event/list": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of events",
+        "/auth/login": {
+            "post": {
+                "description": "Login. Accounts with two-factor authentication get a challenge instead of a session, see /auth/2fa/verify.",
                 "consumes": [
                     "application/json"
                 ],
@@ -664,36 +750,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Get a list of events",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "Page",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Business ID",
-                        "name": "business_id",
-                        "in": "query"
+                        "description": "User",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.EventList"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -705,14 +780,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/event/remove-participant": {
-            "delete": {
+        "/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a participant from an event",
+                "description": "Logout",
                 "consumes": [
                     "application/json"
                 ],
@@ -720,20 +795,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
-                ],
-                "summary": "Remove a participant from an event",
-                "parameters": [
-                    {
-                        "description": "Participant object",
-                        "name": "participant",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.EventParticipant"
-                        }
-                    }
+                    "auth"
                 ],
+                "summary": "Logout",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -750,14 +814,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/event/{id}": {
+        "/auth/oidc/callback": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get an event by ID",
+                "description": "The identity provider redirects here. The provider account is linked to the user with the same verified email, or a new user is created. Responds like /auth/login.",
                 "consumes": [
                     "application/json"
                 ],
@@ -765,15 +824,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Get an event by ID",
+                "summary": "Finish social login",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State returned by the provider",
+                        "name": "state",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -781,7 +847,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Event"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -789,16 +855,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete an event",
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Returns the URL of the identity provider's login page. After logging in there the user is sent back to /auth/oidc/callback.",
                 "consumes": [
                     "application/json"
                 ],
@@ -806,23 +881,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "auth"
                 ],
-                "summary": "Delete an event",
+                "summary": "Start social login",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Platform (web, mobile, admin), web by default",
+                        "name": "platform",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
+                            "$ref": "#/definitions/entity.OidcLoginResponse"
                         }
                     },
                     "400": {
@@ -830,18 +904,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/notification": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update a notification",
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access/refresh token pair. Every refresh token can be used only once; presenting a used token revokes the whole session.",
                 "consumes": [
                     "application/json"
                 ],
@@ -849,17 +924,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "auth"
                 ],
-                "summary": "Update a notification",
+                "summary": "Refresh access token",
                 "parameters": [
                     {
-                        "description": "Notification object",
-                        "name": "notification",
+                        "description": "Refresh token",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.RefreshTokenRequest"
                         }
                     }
                 ],
@@ -867,7 +942,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.TokenResponse"
                         }
                     },
                     "400": {
@@ -875,16 +950,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/auth/register": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a notification and send an email to the user",
+                "description": "Register",
                 "consumes": [
                     "application/json"
                 ],
@@ -892,25 +970,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "auth"
                 ],
-                "summary": "Create a new notification",
+                "summary": "Register",
                 "parameters": [
                     {
-                        "description": "Notification data",
+                        "description": "User",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.RegisterRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.User"
                         }
                     },
                     "400": {
@@ -922,14 +1000,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/list": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of notifications",
+        "/auth/resend-otp": {
+            "post": {
+                "description": "Sends a new email verification code, at most once per cooldown period. The response doesn't tell whether the email is registered.",
                 "consumes": [
                     "application/json"
                 ],
@@ -937,36 +1010,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "auth"
                 ],
-                "summary": "Get a list of notifications",
+                "summary": "Resend verification code",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "user_id",
-                        "name": "user_id",
-                        "in": "query"
+                        "description": "Email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ResendOtpRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.NotificationList"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -978,14 +1040,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/status": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update a notification's status",
+        "/auth/reset-password": {
+            "post": {
+                "description": "Sets a new password using the code sent by forgot-password and logs the user out of every session",
                 "consumes": [
                     "application/json"
                 ],
@@ -993,17 +1050,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "auth"
                 ],
-                "summary": "Update a notification status",
+                "summary": "Reset password",
                 "parameters": [
                     {
-                        "description": "Notification object",
-                        "name": "notification",
+                        "description": "Reset password",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.ResetPasswordRequest"
                         }
                     }
                 ],
@@ -1011,7 +1068,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -1023,14 +1080,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/update-status": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update status notification by ID",
+        "/auth/verify-email": {
+            "post": {
+                "description": "Register",
                 "consumes": [
                     "application/json"
                 ],
@@ -1038,17 +1090,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "auth"
                 ],
-                "summary": "Update status a notification by ID",
+                "summary": "Register",
                 "parameters": [
                     {
-                        "description": "Notification object",
-                        "name": "notification",
+                        "description": "User",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.VerifyEmail"
                         }
                     }
                 ],
@@ -1056,7 +1108,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.User"
                         }
                     },
                     "400": {
@@ -1068,14 +1120,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/{id}": {
-            "get": {
+        "/business": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a notification by ID",
+                "description": "Update a business",
                 "consumes": [
                     "application/json"
                 ],
@@ -1083,23 +1135,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notification"
+                    "business"
                 ],
-                "summary": "Get a notification by ID",
+                "summary": "Update a business",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Notification ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Business object",
+                        "name": "business",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Business"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Notification"
+                            "$ref": "#/definitions/entity.Business"
                         }
                     },
                     "400": {
@@ -1107,59 +1161,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete a notification",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "notification"
-                ],
-                "summary": "Delete a notification",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Notification ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/report": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a report",
+                "description": "Create a new business entity",
                 "consumes": [
                     "application/json"
                 ],
@@ -1167,17 +1184,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "report"
+                    "business"
                 ],
-                "summary": "Update a report",
+                "summary": "Create a new business",
                 "parameters": [
                     {
-                        "description": "Report object",
-                        "name": "report",
+                        "description": "Business data",
+                        "name": "business",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Report"
+                            "$ref": "#/definitions/entity.Business"
                         }
                     }
                 ],
@@ -1185,7 +1202,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Report"
+                            "$ref": "#/definitions/entity.Business"
                         }
                     },
                     "400": {
@@ -1193,16 +1210,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/business/invitations/accept": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a report",
+                "description": "Joins the business with the code from the invitation email. The caller must be logged in with the invited email address.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1210,17 +1235,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "report"
+                    "business"
                 ],
-                "summary": "Create a report",
+                "summary": "Accept an invitation",
                 "parameters": [
                     {
-                        "description": "Report object",
-                        "name": "report",
+                        "description": "Invitation code",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Report"
+                            "$ref": "#/definitions/entity.AcceptBusinessInvitationRequest"
                         }
                     }
                 ],
@@ -1228,7 +1253,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Report"
+                            "$ref": "#/definitions/entity.BusinessMember"
                         }
                     },
                     "400": {
@@ -1236,18 +1261,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/report/list": {
+        "/business/list": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of reports",
+                "description": "Get a list of businesses",
                 "consumes": [
                     "application/json"
                 ],
@@ -1255,9 +1292,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "report"
+                    "business"
                 ],
-                "summary": "Get a list of reports",
+                "summary": "Get a list of businesses",
                 "parameters": [
                     {
                         "type": "number",
@@ -1275,8 +1312,80 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "business_id",
-                        "name": "business_id",
+                        "description": "owner_id",
+                        "name": "owner_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the businesses of the logged in user, replaces owner_id",
+                        "name": "mine",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude of the point to search around, requires lng",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude of the point to search around, requires lat",
+                        "name": "lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only businesses within this many meters of lat, lng",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only businesses that are open at the moment",
+                        "name": "open_now",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only businesses in this category or below it, by ID or slug",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search words, matched against name, categories, description and reviews. Quote phrases and put - before words to leave out.",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "relevance (default with q) for the best match first, rating for the best rated first, newest (default without q) for the newest first",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only businesses rated at least this, 0 to 5",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only businesses of these price levels, comma separated, e.g. 1,2",
+                        "name": "price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add the counts of the businesses by category, rating, price level, being open and distance",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the category names in facets, en by default",
+                        "name": "lang",
                         "in": "query"
                     }
                 ],
@@ -1284,7 +1393,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.ReportList"
+                            "$ref": "#/definitions/entity.BusinessList"
                         }
                     },
                     "400": {
@@ -1296,14 +1405,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/report/{id}": {
+        "/business/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a report by ID",
+                "description": "Get a business by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1311,13 +1420,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "report"
+                    "business"
                 ],
-                "summary": "Get a report by ID",
+                "summary": "Get a business by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Report ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1327,7 +1436,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Report"
+                            "$ref": "#/definitions/entity.Business"
                         }
                     },
                     "400": {
@@ -1344,7 +1453,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a report",
+                "description": "Delete a business",
                 "consumes": [
                     "application/json"
                 ],
@@ -1352,13 +1461,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "report"
+                    "business"
                 ],
-                "summary": "Delete a report",
+                "summary": "Delete a business",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Report ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1376,18 +1485,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/review": {
-            "put": {
+        "/business/{id}/hours": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a review",
+                "description": "Returns the weekly hours and the overrides of the upcoming dates.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1395,42 +1510,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "review"
+                    "business"
                 ],
-                "summary": "Update a review",
+                "summary": "Get the opening hours of a business",
                 "parameters": [
                     {
-                        "description": "Review object",
-                        "name": "review",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.Review"
-                        }
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Review"
+                            "$ref": "#/definitions/entity.BusinessSchedule"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a review",
+                "description": "Replaces all weekly hours. day_of_week is 0 for Sunday to 6 for Saturday, times are HH:MM in the business's timezone and a day may have several intervals. An interval that closes at or before it opens ends on the next day. Days without intervals are closed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1438,17 +1551,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "review"
+                    "business"
                 ],
-                "summary": "Create a review",
+                "summary": "Set the weekly opening hours of a business",
                 "parameters": [
                     {
-                        "description": "Review object",
-                        "name": "review",
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weekly hours",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Review"
+                            "$ref": "#/definitions/entity.BusinessHoursRequest"
                         }
                     }
                 ],
@@ -1456,7 +1576,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Review"
+                            "$ref": "#/definitions/entity.BusinessSchedule"
                         }
                     },
                     "400": {
@@ -1464,18 +1584,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/review/list": {
-            "get": {
+        "/business/{id}/hours/overrides/{date}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of reviews",
+                "description": "Replaces the weekly hours on one date, either with other intervals or with \"closed\": true.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1483,36 +1609,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "review"
+                    "business"
                 ],
-                "summary": "Get a list of reviews",
+                "summary": "Set special hours for a date",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query",
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query",
+                        "type": "string",
+                        "description": "Date as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "business_id",
-                        "name": "business_id",
-                        "in": "query"
+                        "description": "Special hours",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BusinessHourOverrideRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.ReviewList"
+                            "$ref": "#/definitions/entity.BusinessSchedule"
                         }
                     },
                     "400": {
@@ -1520,46 +1649,9 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/review/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a review by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "review"
-                ],
-                "summary": "Get a review by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Review ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/entity.Review"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -1572,7 +1664,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a review",
+                "description": "The business is open on its weekly hours on that date again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1580,16 +1672,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "review"
+                    "business"
                 ],
-                "summary": "Delete a review",
+                "summary": "Remove the special hours of a date",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Review ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1599,8 +1698,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -1608,14 +1713,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/review/{id}/image": {
+        "/business/{id}/image": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload an image for a specific review",
+                "description": "Uploads an image to the business's gallery and makes it the cover",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -1623,13 +1728,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "review"
+                    "business"
                 ],
-                "summary": "Set an image for a review",
+                "summary": "Set an image for a business",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Review ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1646,7 +1751,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Review"
+                            "$ref": "#/definitions/entity.Business"
                         }
                     },
                     "400": {
@@ -1655,53 +1760,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/session": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update a session",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "session"
-                ],
-                "summary": "Update a session",
-                "parameters": [
-                    {
-                        "description": "Session object",
-                        "name": "session",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.Session"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/entity.Session"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -1709,14 +1775,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/session/list": {
+        "/business/{id}/invitations": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of users",
+                "description": "Lists invitations, newest first. Managers and the owner can see them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1724,10 +1790,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "business"
                 ],
-                "summary": "Get a list of users",
+                "summary": "Get the invitations of a business",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "number",
                         "description": "page",
@@ -1744,8 +1817,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "user_id",
-                        "name": "user_id",
+                        "description": "pending, accepted or revoked",
+                        "name": "status",
                         "in": "query"
                     }
                 ],
@@ -1753,26 +1826,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SessionList"
+                            "$ref": "#/definitions/entity.BusinessInvitationList"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/session/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a session by ID",
+                "description": "Emails an invitation code to join as manager or editor. Managers can invite editors, only the owner can invite managers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1780,23 +1857,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "business"
                 ],
-                "summary": "Get a session by ID",
+                "summary": "Invite someone to a business",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Who to invite and as what",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BusinessInvitationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Session"
+                            "$ref": "#/definitions/entity.BusinessInvitation"
                         }
                     },
                     "400": {
@@ -1804,16 +1890,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/business/{id}/invitations/{invitation_id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a session",
+                "description": "Revokes a pending invitation so its code stops working",
                 "consumes": [
                     "application/json"
                 ],
@@ -1821,16 +1921,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "business"
                 ],
-                "summary": "Delete a session",
+                "summary": "Revoke an invitation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "invitation_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1840,8 +1947,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -1849,14 +1962,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user": {
-            "put": {
+        "/business/{id}/members": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a user",
+                "description": "Lists the owner, managers and editors of a business. Only members can see them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1864,42 +1977,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "business"
                 ],
-                "summary": "Update a user",
+                "summary": "Get the members of a business",
                 "parameters": [
                     {
-                        "description": "User object",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.User"
-                        }
-                    }
-                ],
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.BusinessMemberList"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/business/{id}/members/{user_id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new user",
+                "description": "Makes a member a manager or an editor. Only the owner can do this, ownership moves with /business/{id}/transfer.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1907,25 +2040,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "business"
                 ],
-                "summary": "Create a new user",
+                "summary": "Change a member's role",
                 "parameters": [
                     {
-                        "description": "User object",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID of the member",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "manager or editor",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.BusinessMemberRoleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.BusinessMember"
                         }
                     },
                     "400": {
@@ -1933,34 +2080,51 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/user/avatar": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload an avatar to MinIO and update user's avatar ID in the database",
+                "description": "Members can leave on their own. The owner removes anyone, managers remove editors. The owner can't leave before transferring ownership.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "business"
                 ],
-                "summary": "Set user avatar",
+                "summary": "Remove a member",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Avatar image file",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID of the member",
+                        "name": "user_id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -1968,7 +2132,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.SuccessResponse"
                         }
                     },
                     "400": {
@@ -1977,8 +2141,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
@@ -1986,14 +2156,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/list": {
+        "/business/{id}/photos": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of users",
+                "description": "The cover comes first, then the gallery in order",
                 "consumes": [
                     "application/json"
                 ],
@@ -2001,10 +2171,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "business"
                 ],
-                "summary": "Get a list of users",
+                "summary": "Get the photos of a business",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "number",
                         "description": "page",
@@ -2018,19 +2195,13 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.UserList"
+                            "$ref": "#/definitions/entity.PhotoList"
                         }
                     },
                     "400": {
@@ -2040,16 +2211,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/user/upload": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload an image to MinIO without saving data to the database",
+                "description": "Uploads a photo to the end of the gallery. The first photo becomes the cover.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -2057,57 +2226,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "upload"
+                    "business"
                 ],
-                "summary": "Upload an image to MinIO",
+                "summary": "Add a photo to a business",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "file",
-                        "description": "Image file to upload",
+                        "description": "Image file",
                         "name": "file",
                         "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caption",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Make the photo the cover",
+                        "name": "is_cover",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Photo"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/user/{id}": {
-            "get": {
+        "/business/{id}/transfer": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a user by ID",
+                "description": "Makes another member the owner. The previous owner stays on as a manager.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2115,23 +2294,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "business"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Transfer ownership of a business",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Business ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "The new owner, must already be a member",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.BusinessTransferRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/entity.BusinessMember"
                         }
                     },
                     "400": {
@@ -2139,16 +2327,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entity.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/category/list": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a user",
+                "description": "Lists categories by slug, every category has the id of its parent so clients can build the tree",
                 "consumes": [
                     "application/json"
                 ],
@@ -2156,23 +2358,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "category"
                 ],
-                "summary": "Delete a user",
+                "summary": "Get a list of categories",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the direct children of this category",
+                        "name": "parent_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.SuccessResponse"
+                            "$ref": "#/definitions/entity.CategoryList"
                         }
                     },
                     "400": {
@@ -2183,269 +2398,4161 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.Business": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "category": {
-                    "type": "string"
-                },
-                "contact_info": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "owner_id": {
-                    "type": "string"
-                },
-                "photos": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
         },
-        "entity.BusinessList": {
-            "type": "object",
-            "properties": {
-                "businesses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/entity.Business"
+        "/category/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
+                ],
+                "description": "Get a category by its ID or slug",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Get a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Category"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Update an event",
+                "parameters": [
+                    {
+                        "description": "Event object",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Create an event",
+                "parameters": [
+                    {
+                        "description": "Event object",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event/:id/participants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get participants of an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get participants of an event",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "event_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EventParticipantList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event/add-participant": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a participant to an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Add a participant to an event",
+                "parameters": [
+                    {
+                        "description": "Participant object",
+                        "name": "participant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.EventParticipant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EventParticipant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of events",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get a list of events",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Business ID",
+                        "name": "business_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EventList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event/remove-participant": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a participant from an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Remove a participant from an event",
+                "parameters": [
+                    {
+                        "description": "Participant object",
+                        "name": "participant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.EventParticipant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an event by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get an event by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Delete an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a notification",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Update a notification",
+                "parameters": [
+                    {
+                        "description": "Notification object",
+                        "name": "notification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a notification and send an email to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Create a new notification",
+                "parameters": [
+                    {
+                        "description": "Notification data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of notifications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Get a list of notifications",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.NotificationList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a notification's status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Update a notification status",
+                "parameters": [
+                    {
+                        "description": "Notification object",
+                        "name": "notification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification/update-status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update status notification by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Update status a notification by ID",
+                "parameters": [
+                    {
+                        "description": "Notification object",
+                        "name": "notification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a notification by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Get a notification by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a notification",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Delete a notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a photo by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photo"
+                ],
+                "summary": "Get a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Photo"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the caption or position of a photo or makes it the cover. Only the fields given change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photo"
+                ],
+                "summary": "Update a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PhotoUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Photo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the photo from its gallery and from storage. The next photo becomes the cover when the cover is deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photo"
+                ],
+                "summary": "Delete a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rbac/policies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the routes each role may call",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Get policies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only policies of this role",
+                        "name": "role",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PolicyList"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows a role to call the routes matching path with the methods matching methods, e.g. \"GET|POST\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Add a policy",
+                "parameters": [
+                    {
+                        "description": "Policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Policy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Policy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a policy, the role keeps what other policies and its granted roles allow",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Remove a policy",
+                "parameters": [
+                    {
+                        "description": "Policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Policy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rbac/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists which roles inherit the policies of which other roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Get role grants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.RoleGrantList"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lets subject do everything role is allowed to do",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Grant a role",
+                "parameters": [
+                    {
+                        "description": "Role grant",
+                        "name": "grant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.RoleGrant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.RoleGrant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops subject from inheriting the policies of role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Revoke a role",
+                "parameters": [
+                    {
+                        "description": "Role grant",
+                        "name": "grant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.RoleGrant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Update a report",
+                "parameters": [
+                    {
+                        "description": "Report object",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Create a report",
+                "parameters": [
+                    {
+                        "description": "Report object",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of reports",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Get a list of reports",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "business_id",
+                        "name": "business_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReportList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a report by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Get a report by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Delete a report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a review, the previous version stays in its history. New feedback goes through the pre-screen again and an edited rejected review waits for an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Update a review",
+                "parameters": [
+                    {
+                        "description": "Review object",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "A user has one review per business, posting again for the same business updates the existing review. Reviews the pre-screen flags stay pending until an admin approves them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Create a review",
+                "parameters": [
+                    {
+                        "description": "Review object",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of reviews",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Get a list of reviews",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "business_id",
+                        "name": "business_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "newest (default), helpful for the most useful first, rating_high or rating_low",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, published (default) or rejected, only admins can list other than published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReviewList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a review by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Get a review by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a review",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Delete a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every version of a review newest first, with what changed compared to the version before. Only the author and admins can see it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Get the history of a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReviewRevisionList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}/image": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads an image to the review's gallery and makes it the cover",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Set an image for a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}/photos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The cover comes first, then the gallery in order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Get the photos of a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PhotoList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a photo to the end of the review's gallery. The first photo becomes the cover.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Add a photo to a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caption",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Make the photo the cover",
+                        "name": "is_cover",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Photo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}/reactions/{type}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a review useful, funny or cool. Each type counts once per user, reacting again changes nothing. Users can't react to their own reviews.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "React to a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "useful, funny or cool",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the caller's reaction of the given type, it is no error when there is none",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Take back a reaction to a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "useful, funny or cool",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/review/{id}/reply": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Writes or replaces the public reply of the reviewed business, any member of the business can. The reviewer is notified of a new reply.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Reply to a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reply",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReviewReplyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ReviewReply"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Any member of the reviewed business can delete its reply",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Delete the reply to a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/suggest": {
+            "get": {
+                "description": "Returns business, category and event names that start with or resemble q, best match first. Open a suggestion by its type and id. No login is needed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Suggest names while typing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Typed text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Number of suggestions, 10 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of category names, en by default",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuggestionList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Update a session",
+                "parameters": [
+                    {
+                        "description": "Session object",
+                        "name": "session",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Session"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/devices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the caller's active sessions with the browser and operating system they were opened from, most recently used first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "List my devices",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.DeviceList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes every session of the caller except the one making the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Log out all other devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.RowsEffected"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/devices/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes one of the caller's sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Log out a device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get a list of users",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SessionList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a session by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get a session by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Delete a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "description": "User object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads an avatar and makes it the current one, earlier avatars stay in /user/{id}/photos",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set user avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get a list of users",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists successful and failed logins to the caller's account, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get my login history",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.LoginEventList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload an image to MinIO without saving data to the database",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Upload an image to MinIO",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/photos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The current avatar comes first, then the earlier ones in order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get the avatars of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PhotoList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.AcceptBusinessInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Business": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Category"
+                    }
+                },
+                "category_ids": {
+                    "description": "CategoryIDs sets the categories on create and update, leaving it out\nkeeps them. Categories are what the business is listed under.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "contact_info": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_m": {
+                    "description": "DistanceM is only set when listing businesses near a point.",
+                    "type": "number"
+                },
+                "highlight": {
+                    "description": "Highlight is only set when searching, see BusinessHighlight.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BusinessHighlight"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_open_now": {
+                    "description": "IsOpenNow and NextChangeAt are only set when getting a single business.\nNextChangeAt is empty when the business won't open or close within two weeks.",
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "description": "Latitude and Longitude are null until set or geocoded from the address.",
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_change_at": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "photos": {
+                    "type": "string"
+                },
+                "price_level": {
+                    "description": "PriceLevel is 1 (cheap) to 4 (expensive), null when unknown.",
+                    "type": "integer"
+                },
+                "rating_avg": {
+                    "description": "RatingAvg, ReviewCount and RatingHistogram are kept up to date with the\nreviews and ignored on create and update. RatingHistogram counts the\nreviews with 1 to 5 stars.",
+                    "type": "number"
+                },
+                "rating_histogram": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "review_count": {
+                    "type": "integer"
+                },
+                "timezone": {
+                    "description": "Timezone is an IANA name like \"Asia/Tashkent\", opening hours are in it.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessFacets": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.CategoryFacet"
+                    }
+                },
+                "distances": {
+                    "description": "Distances counts the businesses within Value meters, it is empty unless\nlisting near a point.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.FacetCount"
+                    }
+                },
+                "open_now": {
+                    "type": "integer"
+                },
+                "price_levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.FacetCount"
+                    }
+                },
+                "ratings": {
+                    "description": "Ratings counts the businesses rated at least Value.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.FacetCount"
+                    }
+                }
+            }
+        },
+        "entity.BusinessHighlight": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessHourInterval": {
+            "type": "object",
+            "properties": {
+                "closes_at": {
+                    "type": "string"
+                },
+                "opens_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessHourOverride": {
+            "type": "object",
+            "properties": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "description": "2006-01-02",
+                    "type": "string"
+                },
+                "intervals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessHourInterval"
+                    }
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessHourOverrideRequest": {
+            "type": "object",
+            "properties": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "intervals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessHourInterval"
+                    }
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessHours": {
+            "type": "object",
+            "properties": {
+                "closes_at": {
+                    "type": "string"
+                },
+                "day_of_week": {
+                    "description": "0 is Sunday",
+                    "type": "integer"
+                },
+                "opens_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessHoursRequest": {
+            "type": "object",
+            "properties": {
+                "hours": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessHours"
+                    }
+                }
+            }
+        },
+        "entity.BusinessInvitation": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited_by": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessInvitationList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "invitations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessInvitation"
+                    }
+                }
+            }
+        },
+        "entity.BusinessInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessList": {
+            "type": "object",
+            "properties": {
+                "businesses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Business"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "facets": {
+                    "description": "Facets is only set when asked for.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.BusinessFacets"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.BusinessMember": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessMemberList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessMember"
+                    }
+                }
+            }
+        },
+        "entity.BusinessMemberRoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessSchedule": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "hours": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessHours"
+                    }
+                },
+                "overrides": {
+                    "description": "upcoming dates only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BusinessHourOverride"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BusinessTransferRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Category": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "names": {
+                    "description": "Names holds the display name by language code, e.g. {\"en\": \"Cafes\", \"ru\": \"Кафе\"}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "description": "empty for a top level category",
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CategoryFacet": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CategoryList": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Category"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Device": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "browser_version": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "is_current": {
+                    "type": "boolean"
+                },
+                "last_active_at": {
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "os_version": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.DeviceList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "devices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Device"
+                    }
+                }
+            }
+        },
+        "entity.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Event": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.EventList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Event"
+                    }
+                }
+            }
+        },
+        "entity.EventParticipant": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.EventParticipantList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.EventUsers"
+                    }
+                }
+            }
+        },
+        "entity.EventUsers": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_role": {
+                    "type": "string"
+                },
+                "user_type": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.FacetCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.LoginEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
                 },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.LoginEventList": {
+            "type": "object",
+            "properties": {
                 "count": {
                     "type": "integer"
+                },
+                "login_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.LoginEvent"
+                    }
                 }
             }
         },
-        "entity.ErrorResponse": {
+        "entity.LoginRequest": {
             "type": "object",
             "properties": {
-                "code": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Notification": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamp of when the notification was created",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "UUID of the notification",
                     "type": "string"
                 },
                 "message": {
+                    "description": "Message of the notification",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "ownerrole": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status of the notification ('read' or 'unread')",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "UUID of the user who the notification belongs to",
                     "type": "string"
                 }
             }
         },
-        "entity.Event": {
+        "entity.NotificationList": {
             "type": "object",
             "properties": {
-                "business_id": {
+                "notifications": {
+                    "description": "List of notifications",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Notification"
+                    }
+                },
+                "total_count": {
+                    "description": "Total number of notifications",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.OidcLoginResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Photo": {
+            "type": "object",
+            "properties": {
+                "caption": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "date": {
+                "id": {
                     "type": "string"
                 },
-                "description": {
+                "is_cover": {
+                    "description": "IsCover marks the photo shown for its owner, e.g. a user's avatar. Every\ngallery that has photos has exactly one cover.",
+                    "type": "boolean"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "owner_type": {
+                    "description": "business, review or user",
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uploaded_by": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.PhotoList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "photos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Photo"
+                    }
+                }
+            }
+        },
+        "entity.PhotoUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "is_cover": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Policy": {
+            "type": "object",
+            "properties": {
+                "methods": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.PolicyList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "policies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Policy"
+                    }
+                }
+            }
+        },
+        "entity.RefreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Report": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "location": {
+                "reason": {
                     "type": "string"
                 },
-                "name": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "entity.EventList": {
+        "entity.ReportList": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "events": {
+                "reports": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Event"
+                        "$ref": "#/definitions/entity.Report"
                     }
                 }
             }
         },
-        "entity.EventParticipant": {
+        "entity.ResendOtpRequest": {
             "type": "object",
             "properties": {
-                "event_id": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                },
+                "otp": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Review": {
+            "type": "object",
+            "properties": {
+                "business_id": {
+                    "type": "string"
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "feedback": {
+                    "type": "string"
+                },
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
-                "joined_at": {
+                "moderation_note": {
+                    "type": "string"
+                },
+                "my_reactions": {
+                    "description": "MyReactions are the reaction types the caller gave the review.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "photos": {
+                    "description": "Assuming JSONB data is stored as a string",
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "Value between 1 and 5",
+                    "type": "integer"
+                },
+                "reactions": {
+                    "description": "Reactions counts the reactions of other users, they are ignored on\ncreate and update.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ReviewReactionCounts"
+                        }
+                    ]
+                },
+                "reply": {
+                    "description": "Reply is the answer of the business, null until a member writes one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ReviewReply"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status is pending, published or rejected, only published reviews are\nlisted and rated. Flags are why the pre-screen held the review back,\nModerationNote is what the admin who decided on it wrote. All three are\nignored on create and update.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ReviewChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {
                     "type": "string"
                 },
-                "user_id": {
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "entity.EventParticipantList": {
+        "entity.ReviewList": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "participants": {
+                "reviews": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.EventUsers"
+                        "$ref": "#/definitions/entity.Review"
                     }
                 }
             }
         },
-        "entity.EventUsers": {
+        "entity.ReviewModerationRequest": {
             "type": "object",
             "properties": {
-                "email": {
+                "note": {
                     "type": "string"
+                }
+            }
+        },
+        "entity.ReviewReactionCounts": {
+            "type": "object",
+            "properties": {
+                "cool": {
+                    "type": "integer"
                 },
-                "event_id": {
-                    "type": "string"
+                "funny": {
+                    "type": "integer"
                 },
-                "full_name": {
+                "useful": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ReviewReply": {
+            "type": "object",
+            "properties": {
+                "author_id": {
                     "type": "string"
                 },
-                "gender": {
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "string"
-                },
-                "user_role": {
+                "message": {
                     "type": "string"
                 },
-                "user_type": {
+                "review_id": {
                     "type": "string"
                 },
-                "username": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "entity.LoginRequest": {
+        "entity.ReviewReplyRequest": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
-                },
-                "platform": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "entity.Notification": {
+        "entity.ReviewRevision": {
             "type": "object",
             "properties": {
+                "changes": {
+                    "description": "Changes compares the revision with the one before it.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ReviewChange"
+                    }
+                },
                 "created_at": {
-                    "description": "Timestamp of when the notification was created",
                     "type": "string"
                 },
-                "email": {
+                "edited_by": {
                     "type": "string"
                 },
-                "id": {
-                    "description": "UUID of the notification",
+                "feedback": {
                     "type": "string"
                 },
-                "message": {
-                    "description": "Message of the notification",
+                "id": {
                     "type": "string"
                 },
-                "owner_id": {
-                    "type": "string"
+                "rating": {
+                    "type": "integer"
                 },
-                "ownerrole": {
+                "review_id": {
                     "type": "string"
                 },
-                "status": {
-                    "description": "Status of the notification ('read' or 'unread')",
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ReviewRevisionList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ReviewRevision"
+                    }
+                }
+            }
+        },
+        "entity.RoleGrant": {
+            "type": "object",
+            "properties": {
+                "role": {
                     "type": "string"
                 },
-                "user_id": {
-                    "description": "UUID of the user who the notification belongs to",
+                "subject": {
                     "type": "string"
                 }
             }
         },
-        "entity.NotificationList": {
+        "entity.RoleGrantList": {
             "type": "object",
             "properties": {
-                "notifications": {
-                    "description": "List of notifications",
+                "count": {
+                    "type": "integer"
+                },
+                "role_grants": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Notification"
+                        "$ref": "#/definitions/entity.RoleGrant"
                     }
-                },
-                "total_count": {
-                    "description": "Total number of notifications",
+                }
+            }
+        },
+        "entity.RowsEffected": {
+            "type": "object",
+            "properties": {
+                "rows_effected": {
                     "type": "integer"
                 }
             }
         },
-        "entity.RegisterRequest": {
+        "entity.Session": {
             "type": "object",
             "properties": {
-                "email": {
+                "created_at": {
                     "type": "string"
                 },
-                "full_name": {
+                "expires_at": {
                     "type": "string"
                 },
-                "gender": {
+                "id": {
                     "type": "string"
                 },
-                "password": {
+                "ip_address": {
                     "type": "string"
                 },
-                "user_name": {
-                    "type": "string"
-                }
-            }
-        },
-        "entity.Report": {
-            "type": "object",
-            "properties": {
-                "business_id": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_active_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "platform": {
                     "type": "string"
                 },
-                "id": {
+                "updated_at": {
                     "type": "string"
                 },
-                "reason": {
+                "user_agent": {
                     "type": "string"
                 },
                 "user_id": {
@@ -2453,116 +6560,136 @@ const docTemplate = `{
                 }
             }
         },
-        "entity.ReportList": {
+        "entity.SessionList": {
             "type": "object",
             "properties": {
                 "count": {
+                    "description": "sdf",
                     "type": "integer"
                 },
-                "reports": {
+                "sessions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Report"
+                        "$ref": "#/definitions/entity.Session"
                     }
                 }
             }
         },
-        "entity.Review": {
+        "entity.SuccessResponse": {
             "type": "object",
             "properties": {
-                "business_id": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "feedback": {
+                "message": {
                     "type": "string"
-                },
+                }
+            }
+        },
+        "entity.Suggestion": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string"
                 },
-                "photos": {
-                    "description": "Assuming JSONB data is stored as a string",
+                "name": {
                     "type": "string"
                 },
-                "rating": {
-                    "description": "Value between 1 and 5",
-                    "type": "integer"
-                },
-                "user_id": {
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "entity.ReviewList": {
+        "entity.SuggestionList": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "reviews": {
+                "suggestions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Review"
+                        "$ref": "#/definitions/entity.Suggestion"
                     }
                 }
             }
         },
-        "entity.Session": {
+        "entity.TokenResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "expires_at": {
+                "access_token": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
+                "expires_in": {
+                    "type": "integer"
                 },
-                "ip_address": {
+                "refresh_token": {
                     "type": "string"
                 },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "last_active_at": {
+                "session_id": {
                     "type": "string"
-                },
-                "platform": {
+                }
+            }
+        },
+        "entity.TwoFactorDisableRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string"
                 },
-                "updated_at": {
+                "recovery_code": {
                     "type": "string"
-                },
-                "user_agent": {
+                }
+            }
+        },
+        "entity.TwoFactorEnableRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
                     "type": "string"
                 },
-                "user_id": {
+                "code": {
                     "type": "string"
                 }
             }
         },
-        "entity.SessionList": {
+        "entity.TwoFactorEnableResponse": {
             "type": "object",
             "properties": {
-                "count": {
-                    "description": "sdf",
-                    "type": "integer"
-                },
-                "sessions": {
+                "recovery_codes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Session"
+                        "type": "string"
                     }
                 }
             }
         },
-        "entity.SuccessResponse": {
+        "entity.TwoFactorSetupRequest": {
             "type": "object",
             "properties": {
-                "message": {
+                "challenge_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TwoFactorVerifyRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
                     "type": "string"
                 }
             }
@@ -2597,6 +6724,9 @@ const docTemplate = `{
                 "profile_picture": {
                     "type": "string"
                 },
+                "refresh_token": {
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
@@ -2628,6 +6758,44 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.UserStatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "active or blocked",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.UserStatusHistory": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.UserStatusChange"
+                    }
+                }
+            }
+        },
         "entity.VerifyEmail": {
             "type": "object",
             "properties": {
@@ -6,14 +6,18 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/casbin/casbin"
 	"github.com/gin-gonic/gin"
 
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	v1 "github.com/Avazbek-02/udevslab-lesson6/internal/controller/http/v1"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/usecase"
 	minio "github.com/Avazbek-02/udevslab-lesson6/pkg/MinIO"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/geo"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/httpserver"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/jwt"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/policywatcher"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
 	rediscache "github.com/golanguzb70/redis-cache"
 )
@@ -28,9 +32,6 @@ func Run(cfg *config.Config) {
 	}
 	defer pg.Close()
 
-	// Use case
-	useCase := usecase.New(pg, cfg, l)
-
 	// redis
 	redis, err := rediscache.New(&rediscache.Config{
 		RedisHost: cfg.Redis.RedisHost,
@@ -40,6 +41,47 @@ func Run(cfg *config.Config) {
 		l.Fatal(fmt.Errorf("app - Run - rediscache.New: %w", err))
 	}
 
+	// Use case
+	useCase := usecase.New(pg, cfg, l, redis)
+
+	// jwt signing keys
+	jwtKeys, err := jwt.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.ActiveKid, cfg.JWT.Secret, cfg.JWT.HS256Until)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - jwt.LoadKeySet: %w", err))
+	}
+
+	// rbac policy, kept in postgres and reloaded when another instance changes it
+	enforcer, err := casbin.NewSyncedEnforcerSafe("config/rbac.conf")
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - casbin.NewSyncedEnforcerSafe: %w", err))
+	}
+	// casbin logs the whole policy on every load, which the watcher below
+	// does periodically
+	enforcer.EnableLog(false)
+	enforcer.SetAdapter(useCase.PolicyRepo)
+	if err = enforcer.LoadPolicy(); err != nil {
+		l.Fatal(fmt.Errorf("app - Run - enforcer.LoadPolicy: %w", err))
+	}
+
+	policyWatcher := policywatcher.New(redis, config.PolicyReloadPeriod)
+	defer policyWatcher.Close()
+	enforcer.SetWatcher(policyWatcher)
+	_ = policyWatcher.SetUpdateCallback(func(string) {
+		// on an error the adapter has put the previous rules back
+		if err := enforcer.LoadPolicy(); err != nil {
+			l.Error(fmt.Errorf("app - Run - enforcer.LoadPolicy: %w", err))
+		}
+	})
+
+	// places for geocoding business addresses
+	var gazetteer *geo.Gazetteer
+	if cfg.Geo.GeoGazetteer != "" {
+		gazetteer, err = geo.LoadGazetteer(cfg.Geo.GeoGazetteer)
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - geo.LoadGazetteer: %w", err))
+		}
+	}
+
 	// HTTP Server
 	handler := gin.New()
 	//minio
@@ -47,7 +89,7 @@ func Run(cfg *config.Config) {
 	if err != nil {
 		l.Fatal(fmt.Errorf("app - Run - MinIo.New: %w", err))
 	}
-	v1.NewRouter(handler, l, cfg, useCase, redis, minio)
+	v1.NewRouter(handler, l, cfg, useCase, redis, minio, jwtKeys, enforcer, gazetteer)
 
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
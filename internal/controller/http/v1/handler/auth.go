@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,13 +12,14 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/hash"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/jwt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 
 // Login godoc
 // @Router /auth/login [post]
 // @Summary Login
-// @Description Login
+// @Description Login. Accounts with two-factor authentication get a challenge instead of a session, see /auth/2fa/verify.
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -39,52 +41,75 @@ func (h *Handler) Login(ctx *gin.Context) {
 		UserName: body.Email,
 		Email:    body.Email,
 	})
+	if err == pgx.ErrNoRows {
+		h.recordLoginFailure(ctx, entity.User{}, body.Email, body.Platform, "user_not_found")
+	}
 	if h.HandleDbError(ctx, err, "Error getting user") {
 		return
 	}
 
-	if user.UserType == "user" && body.Platform == "admin" {
-		h.ReturnError(ctx, config.ErrorForbidden, "User can't login to admin web", http.StatusBadRequest)
+	if !h.checkPlatform(ctx, user, body.Platform) {
 		return
-	} else if user.UserType == "admin" && body.Platform != "admin" {
-		h.ReturnError(ctx, config.ErrorForbidden, "Admin can only login to admin web", http.StatusBadRequest)
+	}
+
+	if h.loginLocked(ctx, user, body.Platform) {
 		return
 	}
 	
 	if !hash.CheckPasswordHash(body.Password, user.Password) {
+		h.loginFailed(ctx, user, body.Platform)
 		h.ReturnError(ctx, config.ErrorInvalidPass, "Incorrect password", http.StatusBadRequest)
 		return
 	}
 
-	// create session
-	newSession := entity.Session{
-		UserID:       user.ID,
-		IPAddress:    ctx.ClientIP(),
-		ExpiresAt:    time.Now().Add(time.Hour * 999999).Format(time.RFC3339),
-		UserAgent:    ctx.Request.UserAgent(),
-		IsActive:     true,
-		LastActiveAt: time.Now().Format(time.RFC3339),
-		Platform:     body.Platform,
+	if !h.checkUserStatus(ctx, user.Status) {
+		h.recordLoginFailure(ctx, user, user.Email, body.Platform, "user_"+user.Status)
+		return
 	}
 
-	session, err := h.UseCase.SessionRepo.Create(ctx, newSession)
-	if h.HandleDbError(ctx, err, "Error while creating new session") {
-		return
+	h.completeLogin(ctx, user, body.Platform)
+}
+
+// checkPlatform writes an error and returns false if the user may not log in to platform.
+func (h *Handler) checkPlatform(ctx *gin.Context, user entity.User, platform string) bool {
+	if user.UserType == "user" && platform == "admin" {
+		h.ReturnError(ctx, config.ErrorForbidden, "User can't login to admin web", http.StatusBadRequest)
+		return false
+	} else if user.UserType == "admin" && platform != "admin" {
+		h.ReturnError(ctx, config.ErrorForbidden, "Admin can only login to admin web", http.StatusBadRequest)
+		return false
 	}
 
-	// generate jwt token
-	jwtFields := map[string]interface{}{
-		"sub":        user.ID,
-		"user_role":  user.UserRole,
-		"user_type":  user.UserType,
-		"platform":   body.Platform,
-		"session_id": session.ID,
-		"email": user.Email,
+	return true
+}
+
+// completeLogin is the part of the login shared by every way of proving who the
+// user is: it hands out a two-factor challenge when needed, otherwise a session.
+func (h *Handler) completeLogin(ctx *gin.Context, user entity.User, platform string) {
+	userTotp, err := h.UseCase.TotpRepo.GetSingle(ctx, entity.Id{ID: user.ID})
+	if err != nil && err != pgx.ErrNoRows {
+		h.HandleDbError(ctx, err, "Error getting totp")
+		return
+	}
+	totpEnabled := err == nil && userTotp.IsEnabled
+
+	if totpEnabled || h.twoFactorRequired(user.UserType) {
+		challenge, err := h.newTwoFactorChallenge(ctx, entity.TwoFactorChallengeState{
+			UserID:        user.ID,
+			Platform:      platform,
+			SetupRequired: !totpEnabled,
+		})
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(200, challenge)
+		return
 	}
 
-	user.AccessToken, err = jwt.GenerateJWT(jwtFields, h.Config.JWT.Secret)
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+	session, err := h.newSession(ctx, &user, platform)
+	if h.HandleDbError(ctx, err, "Error while creating new session") {
 		return
 	}
 
@@ -145,10 +170,15 @@ func (h *Handler) Register(ctx *gin.Context) {
 	}
 
 	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{
-		UserName: body.Username,
-		Email:    body.Email,
+		Email: body.Email,
 	})
-	if err == nil {
+	if err != nil && err != pgx.ErrNoRows {
+		h.HandleDbError(ctx, err, "Error getting user")
+		return
+	}
+	exists := err == nil
+
+	if exists && user.Status != "inverify" {
 		h.ReturnError(ctx, config.ErrorConflict, "User already exists", 400)
 		return
 	}
@@ -159,45 +189,99 @@ func (h *Handler) Register(ctx *gin.Context) {
 		return
 	}
 
-	user, err = h.UseCase.UserRepo.Create(ctx, entity.User{
-		FullName: body.FullName,
-		UserType: "user",
-		UserRole: "user",
-		Username: body.Username,
-		Email:    body.Email,
-		Status:   "inverify",
-		Password: body.Password,
-		Gender:   body.Gender,
-		AvatarId: body.Email,
+	if exists {
+		// The email was never verified, so nobody has proven they own this
+		// account yet: registering again restarts verification with the new details.
+		user, err = h.UseCase.UserRepo.Update(ctx, entity.User{
+			ID:       user.ID,
+			FullName: body.FullName,
+			Username: body.Username,
+			Password: body.Password,
+			Gender:   body.Gender,
+		})
+		if h.HandleDbError(ctx, err, "Error updating user") {
+			return
+		}
+	} else {
+		user, err = h.UseCase.UserRepo.Create(ctx, entity.User{
+			FullName: body.FullName,
+			UserType: "user",
+			UserRole: "user",
+			Username: body.Username,
+			Email:    body.Email,
+			Status:   "inverify",
+			Password: body.Password,
+			Gender:   body.Gender,
+			AvatarId: body.Email,
+		})
+		if h.HandleDbError(ctx, err, "Error creating user") {
+			return
+		}
+	}
+
+	// send verification code to user's email
+	err = h.sendOtp(ctx, fmt.Sprintf("otp-%s", user.Email), user.Email, etc.GenerateOtpEmailBody)
+	if err != nil {
+		h.ReturnOtpError(ctx, err)
+		return
+	}
+
+	ctx.JSON(201, entity.SuccessResponse{
+		Message: "User registered successfully, please verify your email address",
 	})
-	if h.HandleDbError(ctx, err, "Error creating user") {
+}
+
+// ResendOtp godoc
+// @Router /auth/resend-otp [post]
+// @Summary Resend verification code
+// @Description Sends a new email verification code, at most once per cooldown period. The response doesn't tell whether the email is registered.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.ResendOtpRequest true "Email"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) ResendOtp(ctx *gin.Context) {
+	var (
+		body entity.ResendOtpRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil || body.Email == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
 		return
 	}
 
-	// send verification code to user
-	otp := etc.GenerateOTP(6)
-	err = h.Redis.Set(ctx, fmt.Sprintf("otp-%s", user.Email), otp, 5*60)
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Error setting OTP", 500)
+	// Unknown and already verified emails get the same response as the ones
+	// waiting for a code, so the endpoint can't be used to find out who has an
+	// account.
+	response := entity.SuccessResponse{
+		Message: "If the email is waiting for verification, a new code has been sent",
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{
+		Email: body.Email,
+	})
+	if err == pgx.ErrNoRows {
+		ctx.JSON(200, response)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error getting user") {
 		return
 	}
 
-	// send otp code to user's email
-	emailBody, err := etc.GenerateOtpEmailBody(otp)
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Error sending OTP", 500)
+	if user.Status != "inverify" {
+		ctx.JSON(200, response)
 		return
 	}
 
-	err = etc.SendEmail(h.Config.Gmail.Host, h.Config.Gmail.Port, h.Config.Gmail.Email, h.Config.Gmail.EmailPass, body.Email, emailBody)
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Error sending OTP", 500)
+	err = h.sendOtp(ctx, fmt.Sprintf("otp-%s", user.Email), user.Email, etc.GenerateOtpEmailBody)
+	if err != nil && !errors.Is(err, errOtpCooldown) {
+		h.ReturnOtpError(ctx, err)
 		return
 	}
 
-	ctx.JSON(201, entity.SuccessResponse{
-		Message: "User registered successfully, please verify your email address",
-	})
+	ctx.JSON(200, response)
 }
 
 // Register godoc
@@ -221,16 +305,9 @@ func (h *Handler) VerifyEmail(ctx *gin.Context) {
 		return
 	}
 
-	key := fmt.Sprintf("otp-%s", body.Email)
-
-	otp, err := h.Redis.Get(ctx, key)
+	err = h.checkOtp(ctx, fmt.Sprintf("otp-%s", body.Email), body.Otp)
 	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Ooops, something went wrong", http.StatusInternalServerError)
-		return
-	}
-
-	if otp != body.Otp {
-		h.ReturnError(ctx, config.ErrorBadRequest, "Incorrect otp", http.StatusBadRequest)
+		h.ReturnOtpError(ctx, err)
 		return
 	}
 
@@ -248,39 +325,309 @@ func (h *Handler) VerifyEmail(ctx *gin.Context) {
 		return
 	}
 
-	// create session
-	newSession := entity.Session{
+	session, err := h.newSession(ctx, &user, body.Platform)
+	if h.HandleDbError(ctx, err, "Error while creating new session") {
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"user":    user,
+		"session": session,
+	})
+}
+
+// ForgotPassword godoc
+// @Router /auth/forgot-password [post]
+// @Summary Forgot password
+// @Description Sends a one-time password reset code to the user's email
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.ForgotPasswordRequest true "Email"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) ForgotPassword(ctx *gin.Context) {
+	var (
+		body entity.ForgotPasswordRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil || body.Email == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	// The response is the same whether or not the email is registered,
+	// so the endpoint can't be used to find out who has an account.
+	response := entity.SuccessResponse{
+		Message: "If the email is registered, a password reset code has been sent",
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{
+		Email: body.Email,
+	})
+	if err == pgx.ErrNoRows {
+		ctx.JSON(200, response)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error getting user") {
+		return
+	}
+
+	err = h.sendOtp(ctx, fmt.Sprintf("reset-otp-%s", user.Email), user.Email, etc.GeneratePasswordResetEmailBody)
+	if err != nil && !errors.Is(err, errOtpCooldown) {
+		h.ReturnOtpError(ctx, err)
+		return
+	}
+
+	ctx.JSON(200, response)
+}
+
+// ResetPassword godoc
+// @Router /auth/reset-password [post]
+// @Summary Reset password
+// @Description Sets a new password using the code sent by forgot-password and logs the user out of every session
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.ResetPasswordRequest true "Reset password"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) ResetPassword(ctx *gin.Context) {
+	var (
+		body entity.ResetPasswordRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil || body.Email == "" || body.NewPassword == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	err = h.checkOtp(ctx, fmt.Sprintf("reset-otp-%s", body.Email), body.Otp)
+	if err != nil {
+		h.ReturnOtpError(ctx, err)
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{
+		Email: body.Email,
+	})
+	if h.HandleDbError(ctx, err, "get single user") {
+		return
+	}
+
+	user.Password, err = hash.HashPassword(body.NewPassword)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.UseCase.UserRepo.Update(ctx, entity.User{
+		ID:       user.ID,
+		Password: user.Password,
+	})
+	if h.HandleDbError(ctx, err, "update user password") {
+		return
+	}
+
+	// log the user out everywhere, whoever knew the old password may still hold a session
+	_, err = h.UseCase.SessionRepo.UpdateField(ctx, entity.UpdateFieldRequest{
+		Filter: []entity.Filter{{Column: "user_id", Type: "eq", Value: user.ID}},
+		Items:  []entity.UpdateFieldItem{{Column: "is_active", Value: "false"}},
+	})
+	if h.HandleDbError(ctx, err, "Error deactivating sessions") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Password has been reset, please log in with your new password",
+	})
+}
+
+// RefreshToken godoc
+// @Router /auth/refresh [post]
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access/refresh token pair. Every refresh token can be used only once; presenting a used token revokes the whole session.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} entity.TokenResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+func (h *Handler) RefreshToken(ctx *gin.Context) {
+	var (
+		body entity.RefreshTokenRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil || body.RefreshToken == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	token, err := h.UseCase.RefreshTokenRepo.GetSingle(ctx, entity.RefreshToken{
+		TokenHash: hash.HashToken(body.RefreshToken),
+	})
+	if err == pgx.ErrNoRows {
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error getting refresh token") {
+		return
+	}
+
+	if token.IsUsed || token.IsRevoked {
+		h.revokeSession(ctx, token.SessionID)
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Refresh token reuse detected, session revoked", http.StatusUnauthorized)
+		return
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, token.ExpiresAt)
+	if err != nil || time.Now().After(expiresAt) {
+		h.ReturnError(ctx, config.ErrorSessionExpired, "Session is expired", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := h.UseCase.SessionRepo.GetSingle(ctx, entity.Id{ID: token.SessionID})
+	if h.HandleDbError(ctx, err, "Error getting session") {
+		return
+	}
+
+	if !session.IsActive {
+		h.ReturnError(ctx, config.ErrorSessionExpired, "Session is not active", http.StatusUnauthorized)
+		return
+	}
+
+	// The conditional update makes rotation atomic: if two requests race with
+	// the same token only one of them wins, the other is treated as reuse.
+	rows, err := h.UseCase.RefreshTokenRepo.MarkUsed(ctx, entity.Id{ID: token.ID})
+	if h.HandleDbError(ctx, err, "Error rotating refresh token") {
+		return
+	}
+
+	if rows.RowsEffected == 0 {
+		h.revokeSession(ctx, token.SessionID)
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Refresh token reuse detected, session revoked", http.StatusUnauthorized)
+		return
+	}
+
+	// Only the activity is written and only while the session is still
+	// active. The session above may be cached, writing it back whole would
+	// undo a revoke that happened in the meantime.
+	now := time.Now().Format(time.RFC3339)
+	session.IPAddress = ctx.ClientIP()
+	rows, err = h.UseCase.SessionRepo.UpdateField(ctx, entity.UpdateFieldRequest{
+		Filter: []entity.Filter{
+			{Column: "id", Type: "eq", Value: session.ID},
+			{Column: "is_active", Type: "eq", Value: "true"},
+		},
+		Items: []entity.UpdateFieldItem{
+			{Column: "ip_address", Value: session.IPAddress},
+			{Column: "last_active_at", Value: now},
+			{Column: "updated_at", Value: now},
+		},
+	})
+	if h.HandleDbError(ctx, err, "Error updating session") {
+		return
+	}
+
+	if rows.RowsEffected == 0 {
+		h.ReturnError(ctx, config.ErrorSessionExpired, "Session is not active", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: session.UserID})
+	if h.HandleDbError(ctx, err, "Error getting user") {
+		return
+	}
+
+	user.AccessToken, user.RefreshToken, err = h.issueTokens(ctx, user, session)
+	if h.HandleDbError(ctx, err, "Error issuing tokens") {
+		return
+	}
+
+	ctx.JSON(200, entity.TokenResponse{
+		AccessToken:  user.AccessToken,
+		RefreshToken: user.RefreshToken,
+		ExpiresIn:    int(config.AccessTokenExpireTime.Seconds()),
+		SessionID:    session.ID,
+	})
+}
+
+// newSession creates a session for the user and fills in user's access and refresh tokens.
+func (h *Handler) newSession(ctx *gin.Context, user *entity.User, platform string) (entity.Session, error) {
+	session, err := h.UseCase.SessionRepo.Create(ctx, entity.Session{
 		UserID:       user.ID,
 		IPAddress:    ctx.ClientIP(),
-		ExpiresAt:    time.Now().Add(time.Hour * 999999).Format(time.RFC3339),
+		ExpiresAt:    time.Now().Add(config.TokenExpireTime).Format(time.RFC3339),
 		UserAgent:    ctx.Request.UserAgent(),
 		IsActive:     true,
 		LastActiveAt: time.Now().Format(time.RFC3339),
-		Platform:     body.Platform,
+		Platform:     platform,
+	})
+	if err != nil {
+		return entity.Session{}, err
 	}
 
-	session, err := h.UseCase.SessionRepo.Create(ctx, newSession)
-	if h.HandleDbError(ctx, err, "Error while creating new session") {
-		return
+	user.AccessToken, user.RefreshToken, err = h.issueTokens(ctx, *user, session)
+	if err != nil {
+		return entity.Session{}, err
 	}
 
-	// generate jwt token
+	h.loginSucceeded(ctx, *user, platform)
+
+	return session, nil
+}
+
+// issueTokens signs a short-lived access token and stores a new single-use
+// refresh token for the session. The refresh token lives as long as the session.
+func (h *Handler) issueTokens(ctx *gin.Context, user entity.User, session entity.Session) (string, string, error) {
 	jwtFields := map[string]interface{}{
 		"sub":        user.ID,
 		"user_role":  user.UserRole,
 		"user_type":  user.UserType,
-		"platform":   body.Platform,
+		"platform":   session.Platform,
 		"session_id": session.ID,
+		"email":      user.Email,
+		"exp":        time.Now().Add(config.AccessTokenExpireTime).Unix(),
 	}
 
-	user.AccessToken, err = jwt.GenerateJWT(jwtFields, h.Config.JWT.Secret)
+	accessToken, err := jwt.GenerateJWT(jwtFields, h.JWTKeys)
 	if err != nil {
-		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
-		return
+		return "", "", err
 	}
 
-	ctx.JSON(200, gin.H{
-		"user":    user,
-		"session": session,
+	refreshToken, err := etc.GenerateToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = h.UseCase.RefreshTokenRepo.Create(ctx, entity.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hash.HashToken(refreshToken),
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// revokeSession deactivates the session and all of its refresh tokens.
+func (h *Handler) revokeSession(ctx *gin.Context, sessionID string) {
+	_, err := h.UseCase.RefreshTokenRepo.RevokeSession(ctx, entity.Id{ID: sessionID})
+	if err != nil {
+		h.Logger.Error(err, "Error revoking refresh tokens")
+	}
+
+	_, err = h.UseCase.SessionRepo.UpdateField(ctx, entity.UpdateFieldRequest{
+		Filter: []entity.Filter{{Column: "id", Type: "eq", Value: sessionID}},
+		Items:  []entity.UpdateFieldItem{{Column: "is_active", Value: "false"}},
 	})
+	if err != nil {
+		h.Logger.Error(err, "Error deactivating session")
+	}
 }
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/jwt"
 	"github.com/casbin/casbin"
@@ -14,7 +17,7 @@ import (
 
 
 
-func (h *Handler) AuthMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
+func (h *Handler) AuthMiddleware(e *casbin.SyncedEnforcer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var (
 			userRole string
@@ -22,6 +25,11 @@ func (h *Handler) AuthMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 			obj      = c.FullPath()
 		)
 
+		// identity headers may only come from a verified token
+		for _, key := range []string{"sub", "user_role", "user_type", "platform", "session_id", "email"} {
+			c.Request.Header.Del(key)
+		}
+
 		token := c.GetHeader("Authorization")
 		if token == "" {
 			userRole = "unauthorized"
@@ -31,7 +39,14 @@ func (h *Handler) AuthMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 			
 			token = strings.TrimPrefix(token, "Bearer ")
 
-			claims, err := jwt.ParseJWT(token, h.Config.JWT.Secret)
+			claims, err := jwt.ParseJWT(token, h.JWTKeys)
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, entity.ErrorResponse{
+					Message: "Access token is expired",
+					Code:    config.ErrorSessionExpired,
+				})
+				return
+			}
 			if err != nil {
 				userRole = "unauthorized"
 			}
@@ -59,8 +74,30 @@ func (h *Handler) AuthMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session is not active"})
 				return
 			}
+
+			expiresAt, err := time.Parse(time.RFC3339, session.ExpiresAt)
+			if err == nil && time.Now().After(expiresAt) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, entity.ErrorResponse{
+					Message: "Session is expired",
+					Code:    config.ErrorSessionExpired,
+				})
+				return
+			}
+
+			status, err := h.UseCase.UserRepo.GetStatus(c, entity.Id{ID: session.UserID})
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User is invalid"})
+				return
+			}
+
+			if !h.checkUserStatus(c, status) {
+				c.Abort()
+				return
+			}
+
+			h.touchSession(c, session)
 		}
-		ok, err := e.EnforceSafe(userRole, obj, act)
+		ok, err := enforce(e, userRole, obj, act)
 		if err != nil {
 			h.Logger.Error(err, "Error enforcing policy")
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
@@ -75,3 +112,48 @@ func (h *Handler) AuthMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// enforce checks the request against the policy under the enforcer's read
+// lock, so a policy reload or an /rbac change can't rewrite the policy while it
+// is being read. Casbin panics on a broken matcher, that becomes an error.
+func enforce(e *casbin.SyncedEnforcer, sub, obj, act string) (ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return e.Enforce(sub, obj, act), nil
+}
+
+// touchSession refreshes last_active_at, but writes at most once per
+// config.SessionActivityPeriod so busy clients don't turn every request into an UPDATE.
+func (h *Handler) touchSession(c *gin.Context, session entity.Session) {
+	lastActiveAt, err := time.Parse(time.RFC3339, session.LastActiveAt)
+	if err == nil && time.Since(lastActiveAt) < config.SessionActivityPeriod {
+		return
+	}
+
+	_, err = h.UseCase.SessionRepo.UpdateField(c, entity.UpdateFieldRequest{
+		Filter: []entity.Filter{{Column: "id", Type: "eq", Value: session.ID}},
+		Items:  []entity.UpdateFieldItem{{Column: "last_active_at", Value: time.Now().Format(time.RFC3339)}},
+	})
+	if err != nil {
+		h.Logger.Error(err, "Error updating session activity")
+	}
+}
+
+// checkUserStatus writes an error and returns false unless the user is active.
+func (h *Handler) checkUserStatus(c *gin.Context, status string) bool {
+	switch status {
+	case "active":
+		return true
+	case "blocked":
+		h.ReturnError(c, config.ErrorUserBlocked, "User is blocked", http.StatusForbidden)
+	case "inverify":
+		h.ReturnError(c, config.ErrorNotVerified, "Please verify your email address", http.StatusForbidden)
+	default:
+		h.ReturnError(c, config.ErrorInvalidUser, "User is not active", http.StatusForbidden)
+	}
+	return false
+}
@@ -3,11 +3,14 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/geo"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 )
 
 // CreateBusiness godoc
@@ -30,6 +33,26 @@ func (h *Handler) CreateBuisiness(c *gin.Context) {
 	if h.HandleDbError(c, err, "Error getting business") {
 		return
 	}
+	if !h.validCoordinates(c, req) {
+		return
+	}
+	h.geocode(&req)
+
+	if req.Timezone == "" {
+		req.Timezone = config.BusinessDefaultTimezone
+	}
+	if !h.validTimezone(c, req.Timezone) {
+		return
+	}
+
+	if !h.validBusinessPriceLevel(c, req) {
+		return
+	}
+
+	if !h.validBusinessCategories(c, &req) {
+		return
+	}
+
 	req.OwnerID = c.GetHeader("sub")
 	res, err := h.UseCase.BusinessRepo.Create(c, req)
 	if h.HandleDbError(c, err, "Error creating business") {
@@ -71,6 +94,10 @@ func (h *Handler) GetBusiness(ctx *gin.Context) {
 		return
 	}
 
+	if !h.setOpenStatus(ctx, &business) {
+		return
+	}
+
 	ctx.JSON(200, business)
 }
 
@@ -85,30 +112,144 @@ func (h *Handler) GetBusiness(ctx *gin.Context) {
 // @Param page query number true "page"
 // @Param limit query number true "limit"
 // @Param owner_id query string false "owner_id"
+// @Param mine query boolean false "Only the businesses of the logged in user, replaces owner_id"
+// @Param lat query number false "Latitude of the point to search around, requires lng"
+// @Param lng query number false "Longitude of the point to search around, requires lat"
+// @Param radius query number false "Only businesses within this many meters of lat, lng"
+// @Param open_now query boolean false "Only businesses that are open at the moment"
+// @Param category query string false "Only businesses in this category or below it, by ID or slug"
+// @Param q query string false "Search words, matched against name, categories, description and reviews. Quote phrases and put - before words to leave out."
+// @Param sort query string false "relevance (default with q) for the best match first, rating for the best rated first, newest (default without q) for the newest first"
+// @Param min_rating query number false "Only businesses rated at least this, 0 to 5"
+// @Param price query string false "Only businesses of these price levels, comma separated, e.g. 1,2"
+// @Param facets query boolean false "Add the counts of the businesses by category, rating, price level, being open and distance"
+// @Param lang query string false "Language of the category names in facets, en by default"
 // @Success 200 {object} entity.BusinessList
 // @Failure 400 {object} entity.ErrorResponse
 func (h *Handler) GetBusinesses(ctx *gin.Context) {
 	var (
-		req entity.GetListFilter
+		req entity.BusinessListFilter
+		err error
 	)
 
 	page := ctx.DefaultQuery("page", "1")
 	limit := ctx.DefaultQuery("limit", "10")
 	ownerId := ctx.DefaultQuery("owner_id", "")
 
-	if ctx.GetHeader("user_type") == "user" {
-		ownerId = ctx.GetHeader("sub")
+	if mine := ctx.Query("mine"); mine != "" {
+		own, err := strconv.ParseBool(mine)
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "mine must be true or false", http.StatusBadRequest)
+			return
+		}
+		if own {
+			ownerId = ctx.GetHeader("sub")
+		}
 	}
 
 	req.Page, _ = strconv.Atoi(page)
 	req.Limit, _ = strconv.Atoi(limit)
-	req.Filters = append(req.Filters,
-		entity.Filter{
-			Column: "owner_id",
-			Type:   "eq",
-			Value:  ownerId,
-		},
-	)
+	if ownerId != "" {
+		req.Filters = append(req.Filters,
+			entity.Filter{
+				Column: "owner_id",
+				Type:   "eq",
+				Value:  ownerId,
+			},
+		)
+	}
+
+	if !h.bindNear(ctx, &req) {
+		return
+	}
+
+	if key := ctx.Query("category"); key != "" {
+		category, err := h.UseCase.CategoryRepo.GetSingle(ctx, categoryKey(key))
+		if err == pgx.ErrNoRows {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Unknown category "+strconv.Quote(key), http.StatusBadRequest)
+			return
+		}
+		if h.HandleDbError(ctx, err, "Error getting category") {
+			return
+		}
+		req.CategoryID = category.ID
+	}
+
+	if openNow := ctx.Query("open_now"); openNow != "" {
+		req.OpenNow, err = strconv.ParseBool(openNow)
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "open_now must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if minRating := ctx.Query("min_rating"); minRating != "" {
+		req.MinRating, err = strconv.ParseFloat(minRating, 64)
+		if err != nil || req.MinRating < 0 || req.MinRating > 5 {
+			h.ReturnError(ctx, config.ErrorBadRequest, "min_rating must be a number from 0 to 5", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if price := ctx.Query("price"); price != "" {
+		for _, level := range strings.Split(price, ",") {
+			priceLevel, err := strconv.Atoi(strings.TrimSpace(level))
+			if err != nil || !validPriceLevel(priceLevel) {
+				h.ReturnError(ctx, config.ErrorBadRequest, "price must be a comma separated list of price levels from 1 to "+strconv.Itoa(config.BusinessMaxPriceLevel), http.StatusBadRequest)
+				return
+			}
+			req.PriceLevels = append(req.PriceLevels, priceLevel)
+		}
+	}
+
+	if facets := ctx.Query("facets"); facets != "" {
+		withFacets, err := strconv.ParseBool(facets)
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "facets must be true or false", http.StatusBadRequest)
+			return
+		}
+		if withFacets {
+			req.FacetsLang = ctx.DefaultQuery("lang", "en")
+			if !languageCodeRegexp.MatchString(req.FacetsLang) {
+				h.ReturnError(ctx, config.ErrorBadRequest, "lang must be a two letter language code", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	req.Query = strings.TrimSpace(ctx.Query("q"))
+	if utf8.RuneCountInString(req.Query) > config.BusinessSearchMaxLength {
+		h.ReturnError(ctx, config.ErrorBadRequest, "q must have at most "+strconv.Itoa(config.BusinessSearchMaxLength)+" characters", http.StatusBadRequest)
+		return
+	}
+
+	sort := "newest"
+	if req.Query != "" {
+		sort = "relevance"
+	}
+
+	switch ctx.DefaultQuery("sort", sort) {
+	case "relevance":
+		if req.Query == "" {
+			h.ReturnError(ctx, config.ErrorBadRequest, "sort=relevance needs q", http.StatusBadRequest)
+			return
+		}
+		req.OrderByRelevance = true
+	case "rating":
+		req.OrderByRating = true
+	case "newest":
+	default:
+		h.ReturnError(ctx, config.ErrorBadRequest, "sort must be relevance, rating or newest", http.StatusBadRequest)
+		return
+	}
+
+	// nearest first, businesses without coordinates last
+	if req.Near != nil {
+		req.OrderBy = append(req.OrderBy, entity.OrderBy{
+			Column: "distance_m",
+			Order:  "asc",
+		})
+	}
 
 	req.OrderBy = append(req.OrderBy, entity.OrderBy{
 		Column: "created_at",
@@ -134,6 +275,7 @@ func (h *Handler) GetBusinesses(ctx *gin.Context) {
 // @Param business body entity.Business true "Business object"
 // @Success 200 {object} entity.Business
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) UpdateBusiness(ctx *gin.Context) {
 	var (
 		body entity.Business
@@ -145,6 +287,39 @@ func (h *Handler) UpdateBusiness(ctx *gin.Context) {
 		return
 	}
 
+	if !h.validCoordinates(ctx, body) {
+		return
+	}
+
+	if body.Timezone != "" && !h.validTimezone(ctx, body.Timezone) {
+		return
+	}
+
+	if !h.validBusinessPriceLevel(ctx, body) {
+		return
+	}
+
+	if !h.validBusinessCategories(ctx, &body) {
+		return
+	}
+
+	if !h.authorizeBusiness(ctx, body.ID, "editor") {
+		return
+	}
+
+	// a new address without new coordinates moves the business, to nowhere
+	// when it can't be geocoded rather than staying at the old address
+	if body.Address != "" && body.Latitude == nil {
+		current, err := h.UseCase.BusinessRepo.GetSingle(ctx, entity.Id{ID: body.ID})
+		if h.HandleDbError(ctx, err, "Error getting business") {
+			return
+		}
+		if current.Address != body.Address {
+			h.geocode(&body)
+			body.ClearCoordinates = body.Latitude == nil
+		}
+	}
+
 	business, err := h.UseCase.BusinessRepo.Update(ctx, body)
 	if h.HandleDbError(ctx, err, "Error updating business") {
 		return
@@ -164,6 +339,7 @@ func (h *Handler) UpdateBusiness(ctx *gin.Context) {
 // @Param id path string true "Business ID"
 // @Success 200 {object} entity.SuccessResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) DeleteBusiness(ctx *gin.Context) {
 	var (
 		req entity.Id
@@ -171,11 +347,22 @@ func (h *Handler) DeleteBusiness(ctx *gin.Context) {
 
 	req.ID = ctx.Param("id")
 
+	if !h.authorizeBusiness(ctx, req.ID, "owner") {
+		return
+	}
+
+	photoKeys, ok := h.photoObjectKeys(ctx, "business", req.ID)
+	if !ok {
+		return
+	}
+
 	err := h.UseCase.BusinessRepo.Delete(ctx, req)
 	if h.HandleDbError(ctx, err, "Error deleting business") {
 		return
 	}
 
+	h.deletePhotoObjects(photoKeys...)
+
 	ctx.JSON(200, entity.SuccessResponse{
 		Message: "Business deleted successfully",
 	})
@@ -184,7 +371,7 @@ func (h *Handler) DeleteBusiness(ctx *gin.Context) {
 // SetBusinessImage godoc
 // @Router /business/{id}/image [post]
 // @Summary Set an image for a business
-// @Description Upload an image for a specific business
+// @Description Uploads an image to the business's gallery and makes it the cover
 // @Tags business
 // @Accept multipart/form-data
 // @Produce json
@@ -193,6 +380,7 @@ func (h *Handler) DeleteBusiness(ctx *gin.Context) {
 // @Param file formData file true "Image file to upload"
 // @Success 200 {object} entity.Business
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 func (h *Handler) SetBusinessImage(ctx *gin.Context) {
 	// Biznes ID ni olish
@@ -202,35 +390,93 @@ func (h *Handler) SetBusinessImage(ctx *gin.Context) {
 		return
 	}
 
-	// Faylni olish
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorBadRequest, "Error getting file", 400)
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
 		return
 	}
 
-	tempPath := "/tmp/" + file.Filename 
-	if err := ctx.SaveUploadedFile(file, tempPath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+	if _, ok := h.uploadPhoto(ctx, "business", businessID, true); !ok {
 		return
 	}
 
-	filename := uuid.New().String() + "-" + file.Filename
-
-	minioURL, err := h.MinIO.Upload(filename, tempPath)
-	if h.HandleDbError(ctx, err, "Error updating business image") {
+	updatedBusiness, err := h.UseCase.BusinessRepo.GetSingle(ctx, entity.Id{ID: businessID})
+	if h.HandleDbError(ctx, err, "Error getting business") {
 		return
 	}
 
-	updateReq := entity.Business{
-		ID:     businessID,
-		Photos: minioURL,
+	ctx.JSON(200, updatedBusiness)
+}
+
+// bindNear reads the lat, lng and radius query parameters into req.
+func (h *Handler) bindNear(ctx *gin.Context, req *entity.BusinessListFilter) bool {
+	latParam, lngParam, radiusParam := ctx.Query("lat"), ctx.Query("lng"), ctx.Query("radius")
+	if latParam == "" && lngParam == "" {
+		if radiusParam != "" {
+			h.ReturnError(ctx, config.ErrorBadRequest, "radius requires lat and lng", http.StatusBadRequest)
+			return false
+		}
+		return true
+	}
+
+	lat, latErr := strconv.ParseFloat(latParam, 64)
+	lng, lngErr := strconv.ParseFloat(lngParam, 64)
+	if latErr != nil || lngErr != nil || !geo.ValidPoint(lat, lng) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "lat and lng must be given together as valid coordinates", http.StatusBadRequest)
+		return false
+	}
+	req.Near = &entity.GeoPoint{Latitude: lat, Longitude: lng}
+
+	if radiusParam != "" {
+		radius, err := strconv.ParseFloat(radiusParam, 64)
+		if err != nil || radius <= 0 {
+			h.ReturnError(ctx, config.ErrorBadRequest, "radius must be a positive number of meters", http.StatusBadRequest)
+			return false
+		}
+		req.RadiusM = radius
+	}
+
+	return true
+}
+
+// geocode fills in the coordinates of a business that has an address but
+// wasn't given any. Addresses the gazetteer doesn't know stay without.
+func (h *Handler) geocode(business *entity.Business) {
+	if business.Latitude != nil || business.Longitude != nil || business.Address == "" {
+		return
 	}
 
-	updatedBusiness, err := h.UseCase.BusinessRepo.Update(ctx, updateReq)
-	if h.HandleDbError(ctx, err, "Error updating business image") {
+	place, ok := h.Gazetteer.Geocode(business.Address)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(200, updatedBusiness)
+	business.Latitude, business.Longitude = &place.Latitude, &place.Longitude
+}
+
+// validCoordinates writes an error unless the business has both or neither coordinate.
+func (h *Handler) validCoordinates(ctx *gin.Context, business entity.Business) bool {
+	if business.Latitude == nil && business.Longitude == nil {
+		return true
+	}
+
+	if business.Latitude == nil || business.Longitude == nil || !geo.ValidPoint(*business.Latitude, *business.Longitude) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "latitude and longitude must be given together as valid coordinates", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// validBusinessPriceLevel writes an error when the business has a price level
+// out of range, leaving it out is fine.
+func (h *Handler) validBusinessPriceLevel(ctx *gin.Context, business entity.Business) bool {
+	if business.PriceLevel != nil && !validPriceLevel(*business.PriceLevel) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "price_level must be from 1 to "+strconv.Itoa(config.BusinessMaxPriceLevel), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func validPriceLevel(level int) bool {
+	return level >= 1 && level <= config.BusinessMaxPriceLevel
 }
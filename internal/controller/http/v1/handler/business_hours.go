@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/hours"
+	"github.com/gin-gonic/gin"
+)
+
+// Opening hours are weekly intervals in the business's timezone. An override
+// replaces the hours of a single date, e.g. shorter hours before a holiday or
+// a closure on the holiday itself.
+
+// GetBusinessHours godoc
+// @Router /business/{id}/hours [get]
+// @Summary Get the opening hours of a business
+// @Description Returns the weekly hours and the overrides of the upcoming dates.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Success 200 {object} entity.BusinessSchedule
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetBusinessHours(ctx *gin.Context) {
+	schedule, err := h.UseCase.BusinessHoursRepo.GetSchedule(ctx, entity.Id{ID: ctx.Param("id")})
+	if h.HandleDbError(ctx, err, "Error getting business hours") {
+		return
+	}
+
+	ctx.JSON(200, schedule)
+}
+
+// SetBusinessHours godoc
+// @Router /business/{id}/hours [put]
+// @Summary Set the weekly opening hours of a business
+// @Description Replaces all weekly hours. day_of_week is 0 for Sunday to 6 for Saturday, times are HH:MM in the business's timezone and a day may have several intervals. An interval that closes at or before it opens ends on the next day. Days without intervals are closed.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param body body entity.BusinessHoursRequest true "Weekly hours"
+// @Success 200 {object} entity.BusinessSchedule
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) SetBusinessHours(ctx *gin.Context) {
+	var (
+		body entity.BusinessHoursRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	for i, item := range body.Hours {
+		if item.DayOfWeek < 0 || item.DayOfWeek > 6 {
+			h.ReturnError(ctx, config.ErrorBadRequest, "day_of_week must be between 0 (Sunday) and 6 (Saturday)", http.StatusBadRequest)
+			return
+		}
+		if body.Hours[i].BusinessHourInterval, err = normalizeInterval(item.BusinessHourInterval); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
+		return
+	}
+
+	err = h.UseCase.BusinessHoursRepo.SetHours(ctx, entity.BusinessSchedule{
+		BusinessID: businessID,
+		Hours:      body.Hours,
+	})
+	if h.HandleDbError(ctx, err, "Error setting business hours") {
+		return
+	}
+
+	h.GetBusinessHours(ctx)
+}
+
+// SetBusinessHourOverride godoc
+// @Router /business/{id}/hours/overrides/{date} [put]
+// @Summary Set special hours for a date
+// @Description Replaces the weekly hours on one date, either with other intervals or with "closed": true.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param date path string true "Date as YYYY-MM-DD"
+// @Param body body entity.BusinessHourOverrideRequest true "Special hours"
+// @Success 200 {object} entity.BusinessSchedule
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) SetBusinessHourOverride(ctx *gin.Context) {
+	var (
+		body entity.BusinessHourOverrideRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	date := ctx.Param("date")
+	if _, err = time.Parse(hours.DateLayout, date); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "date must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	if body.Closed == (len(body.Intervals) > 0) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Give either intervals or closed", http.StatusBadRequest)
+		return
+	}
+
+	for i, interval := range body.Intervals {
+		if body.Intervals[i], err = normalizeInterval(interval); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
+		return
+	}
+
+	err = h.UseCase.BusinessHoursRepo.SetOverride(ctx, businessID, entity.BusinessHourOverride{
+		Date:      date,
+		Closed:    body.Closed,
+		Intervals: body.Intervals,
+		Note:      body.Note,
+	})
+	if h.HandleDbError(ctx, err, "Error setting business hour override") {
+		return
+	}
+
+	h.GetBusinessHours(ctx)
+}
+
+// DeleteBusinessHourOverride godoc
+// @Router /business/{id}/hours/overrides/{date} [delete]
+// @Summary Remove the special hours of a date
+// @Description The business is open on its weekly hours on that date again.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param date path string true "Date as YYYY-MM-DD"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) DeleteBusinessHourOverride(ctx *gin.Context) {
+	date := ctx.Param("date")
+	if _, err := time.Parse(hours.DateLayout, date); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "date must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
+		return
+	}
+
+	err := h.UseCase.BusinessHoursRepo.DeleteOverride(ctx, businessID, date)
+	if h.HandleDbError(ctx, err, "Error deleting business hour override") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Business hour override deleted successfully",
+	})
+}
+
+// setOpenStatus fills in whether the business is open now and when that changes.
+func (h *Handler) setOpenStatus(ctx *gin.Context, business *entity.Business) bool {
+	schedule, err := h.UseCase.BusinessHoursRepo.GetSchedule(ctx, entity.Id{ID: business.ID})
+	if h.HandleDbError(ctx, err, "Error getting business hours") {
+		return false
+	}
+
+	s, err := toSchedule(schedule)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Invalid business hours", http.StatusInternalServerError)
+		h.Logger.Error(err, "Error building business hours")
+		return false
+	}
+
+	now := time.Now()
+	isOpen := s.IsOpen(now)
+	business.IsOpenNow = &isOpen
+	if next, ok := s.NextChange(now); ok {
+		business.NextChangeAt = next.Format(time.RFC3339)
+	}
+
+	return true
+}
+
+// validTimezone writes an error unless name is a timezone like "Asia/Tashkent".
+func (h *Handler) validTimezone(ctx *gin.Context, name string) bool {
+	// LoadLocation also takes "" and "Local" for UTC and the server's zone
+	if _, err := time.LoadLocation(name); err != nil || name == "" || name == "Local" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Unknown timezone "+strconv.Quote(name), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// normalizeInterval checks both times and writes them as HH:MM, "24:00" is
+// only allowed as a closing time and becomes "00:00".
+func normalizeInterval(interval entity.BusinessHourInterval) (entity.BusinessHourInterval, error) {
+	if interval.OpensAt == "24:00" {
+		return interval, errInvalidOpensAt
+	}
+
+	opens, err := hours.ParseClock(interval.OpensAt)
+	if err != nil {
+		return interval, err
+	}
+	closes, err := hours.ParseClock(interval.ClosesAt)
+	if err != nil {
+		return interval, err
+	}
+
+	return entity.BusinessHourInterval{
+		OpensAt:  hours.FormatClock(opens),
+		ClosesAt: hours.FormatClock(closes),
+	}, nil
+}
+
+var errInvalidOpensAt = errors.New("opens_at can't be 24:00, use 00:00")
+
+func toSchedule(schedule entity.BusinessSchedule) (hours.Schedule, error) {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return hours.Schedule{}, err
+	}
+
+	s := hours.Schedule{
+		Location:  location,
+		Overrides: make(map[string][]hours.Interval),
+	}
+
+	for _, item := range schedule.Hours {
+		interval, err := toInterval(item.BusinessHourInterval)
+		if err != nil {
+			return hours.Schedule{}, err
+		}
+		s.Weekly[item.DayOfWeek] = append(s.Weekly[item.DayOfWeek], interval)
+	}
+
+	for _, override := range schedule.Overrides {
+		intervals := []hours.Interval{}
+		for _, item := range override.Intervals {
+			interval, err := toInterval(item)
+			if err != nil {
+				return hours.Schedule{}, err
+			}
+			intervals = append(intervals, interval)
+		}
+		s.Overrides[override.Date] = intervals
+	}
+
+	return s, nil
+}
+
+func toInterval(interval entity.BusinessHourInterval) (hours.Interval, error) {
+	opens, err := hours.ParseClock(interval.OpensAt)
+	if err != nil {
+		return hours.Interval{}, err
+	}
+	closes, err := hours.ParseClock(interval.ClosesAt)
+	if err != nil {
+		return hours.Interval{}, err
+	}
+
+	return hours.Interval{Opens: opens, Closes: closes}, nil
+}
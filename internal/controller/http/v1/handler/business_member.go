@@ -0,0 +1,466 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/etc"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/hash"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// Members of a business are its owner, managers and editors. Editors edit the
+// listing and its events, managers also invite and remove editors, and the
+// owner manages everyone, transfers ownership and deletes the business.
+
+// GetBusinessMembers godoc
+// @Router /business/{id}/members [get]
+// @Summary Get the members of a business
+// @Description Lists the owner, managers and editors of a business. Only members can see them.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.BusinessMemberList
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetBusinessMembers(ctx *gin.Context) {
+	var (
+		req entity.GetListFilter
+	)
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
+		return
+	}
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "m.business_id",
+		Type:   "eq",
+		Value:  businessID,
+	})
+
+	req.OrderBy = append(req.OrderBy, entity.OrderBy{
+		Column: "m.created_at",
+		Order:  "asc",
+	})
+
+	members, err := h.UseCase.BusinessMemberRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting business members") {
+		return
+	}
+
+	ctx.JSON(200, members)
+}
+
+// UpdateBusinessMemberRole godoc
+// @Router /business/{id}/members/{user_id} [put]
+// @Summary Change a member's role
+// @Description Makes a member a manager or an editor. Only the owner can do this, ownership moves with /business/{id}/transfer.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param user_id path string true "User ID of the member"
+// @Param body body entity.BusinessMemberRoleRequest true "manager or editor"
+// @Success 200 {object} entity.BusinessMember
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) UpdateBusinessMemberRole(ctx *gin.Context) {
+	var (
+		body entity.BusinessMemberRoleRequest
+	)
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if body.Role != "manager" && body.Role != "editor" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "role must be manager or editor", http.StatusBadRequest)
+		return
+	}
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "owner") {
+		return
+	}
+
+	member, err := h.UseCase.BusinessMemberRepo.UpdateRole(ctx, entity.BusinessMember{
+		BusinessID: businessID,
+		UserID:     ctx.Param("user_id"),
+		Role:       body.Role,
+	})
+	if h.HandleDbError(ctx, err, "Error updating business member") {
+		return
+	}
+
+	ctx.JSON(200, member)
+}
+
+// RemoveBusinessMember godoc
+// @Router /business/{id}/members/{user_id} [delete]
+// @Summary Remove a member
+// @Description Members can leave on their own. The owner removes anyone, managers remove editors. The owner can't leave before transferring ownership.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param user_id path string true "User ID of the member"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RemoveBusinessMember(ctx *gin.Context) {
+	businessID, userID := ctx.Param("id"), ctx.Param("user_id")
+
+	callerRole, ok := h.businessRole(ctx, businessID)
+	if !ok {
+		return
+	}
+
+	member, err := h.UseCase.BusinessMemberRepo.GetSingle(ctx, entity.BusinessMember{
+		BusinessID: businessID,
+		UserID:     userID,
+	})
+	if h.HandleDbError(ctx, err, "Error getting business member") {
+		return
+	}
+
+	if member.Role == "owner" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "The owner can't be removed, transfer the ownership first", http.StatusBadRequest)
+		return
+	}
+
+	if userID != ctx.GetHeader("sub") && businessRoles[callerRole] <= businessRoles[member.Role] {
+		h.forbidden(ctx, "You can't remove this member")
+		return
+	}
+
+	err = h.UseCase.BusinessMemberRepo.Delete(ctx, member)
+	if h.HandleDbError(ctx, err, "Error removing business member") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Member removed successfully",
+	})
+}
+
+// TransferBusinessOwnership godoc
+// @Router /business/{id}/transfer [post]
+// @Summary Transfer ownership of a business
+// @Description Makes another member the owner. The previous owner stays on as a manager.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param body body entity.BusinessTransferRequest true "The new owner, must already be a member"
+// @Success 200 {object} entity.BusinessMember
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) TransferBusinessOwnership(ctx *gin.Context) {
+	var (
+		body entity.BusinessTransferRequest
+	)
+
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.UserID == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "owner") {
+		return
+	}
+
+	member, err := h.UseCase.BusinessMemberRepo.TransferOwnership(ctx, entity.BusinessMember{
+		BusinessID: businessID,
+		UserID:     body.UserID,
+	})
+	if h.HandleDbError(ctx, err, "Error transferring business") {
+		return
+	}
+
+	ctx.JSON(200, member)
+}
+
+// InviteBusinessMember godoc
+// @Router /business/{id}/invitations [post]
+// @Summary Invite someone to a business
+// @Description Emails an invitation code to join as manager or editor. Managers can invite editors, only the owner can invite managers.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param body body entity.BusinessInvitationRequest true "Who to invite and as what"
+// @Success 200 {object} entity.BusinessInvitation
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) InviteBusinessMember(ctx *gin.Context) {
+	var (
+		body entity.BusinessInvitationRequest
+	)
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	body.Email = strings.ToLower(strings.TrimSpace(body.Email))
+	if !strings.Contains(body.Email, "@") {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid email", http.StatusBadRequest)
+		return
+	}
+
+	if body.Role != "manager" && body.Role != "editor" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "role must be manager or editor", http.StatusBadRequest)
+		return
+	}
+
+	businessID := ctx.Param("id")
+	// managers invite editors, only the owner invites managers
+	inviterRole := "manager"
+	if body.Role == "manager" {
+		inviterRole = "owner"
+	}
+	if !h.authorizeBusiness(ctx, businessID, inviterRole) {
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{Email: body.Email})
+	if err == nil {
+		_, err = h.UseCase.BusinessMemberRepo.GetSingle(ctx, entity.BusinessMember{BusinessID: businessID, UserID: user.ID})
+		if err == nil {
+			h.ReturnError(ctx, config.ErrorConflict, "User is already a member of this business", http.StatusBadRequest)
+			return
+		}
+	}
+	if err != nil && err != pgx.ErrNoRows {
+		h.HandleDbError(ctx, err, "Error getting user")
+		return
+	}
+
+	business, err := h.UseCase.BusinessRepo.GetSingle(ctx, entity.Id{ID: businessID})
+	if h.HandleDbError(ctx, err, "Error getting business") {
+		return
+	}
+
+	token, err := etc.GenerateToken(16)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	invitation, err := h.UseCase.BusinessInvitationRepo.Create(ctx, entity.BusinessInvitation{
+		BusinessID: businessID,
+		Email:      body.Email,
+		Role:       body.Role,
+		TokenHash:  hash.HashToken(token),
+		InvitedBy:  ctx.GetHeader("sub"),
+		ExpiresAt:  time.Now().Add(config.BusinessInvitationExpireTime).Format(time.RFC3339),
+	})
+	if h.HandleDbError(ctx, err, "Error creating invitation") {
+		return
+	}
+
+	emailBody, err := etc.GenerateBusinessInvitationEmailBody(business.Name, body.Role, token)
+	if err == nil {
+		err = etc.SendEmail(h.Config.Gmail.Host, h.Config.Gmail.Port, h.Config.Gmail.Email, h.Config.Gmail.EmailPass, body.Email, emailBody)
+	}
+	if err != nil {
+		h.Logger.Error(err, "Error sending invitation")
+		// nobody can accept an invitation they never received
+		_ = h.UseCase.BusinessInvitationRepo.Revoke(ctx, invitation)
+		h.ReturnError(ctx, config.ErrorInternalServer, "Error sending email", http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(200, invitation)
+}
+
+// GetBusinessInvitations godoc
+// @Router /business/{id}/invitations [get]
+// @Summary Get the invitations of a business
+// @Description Lists invitations, newest first. Managers and the owner can see them.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Param status query string false "pending, accepted or revoked"
+// @Success 200 {object} entity.BusinessInvitationList
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetBusinessInvitations(ctx *gin.Context) {
+	var (
+		req entity.GetListFilter
+	)
+
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "manager") {
+		return
+	}
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+	status := ctx.DefaultQuery("status", "")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "business_id",
+		Type:   "eq",
+		Value:  businessID,
+	})
+
+	if status != "" {
+		req.Filters = append(req.Filters, entity.Filter{
+			Column: "status",
+			Type:   "eq",
+			Value:  status,
+		})
+	}
+
+	req.OrderBy = append(req.OrderBy, entity.OrderBy{
+		Column: "created_at",
+		Order:  "desc",
+	})
+
+	invitations, err := h.UseCase.BusinessInvitationRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting invitations") {
+		return
+	}
+
+	ctx.JSON(200, invitations)
+}
+
+// RevokeBusinessInvitation godoc
+// @Router /business/{id}/invitations/{invitation_id} [delete]
+// @Summary Revoke an invitation
+// @Description Revokes a pending invitation so its code stops working
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param invitation_id path string true "Invitation ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RevokeBusinessInvitation(ctx *gin.Context) {
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "manager") {
+		return
+	}
+
+	err := h.UseCase.BusinessInvitationRepo.Revoke(ctx, entity.BusinessInvitation{
+		ID:         ctx.Param("invitation_id"),
+		BusinessID: businessID,
+	})
+	if h.HandleDbError(ctx, err, "Error revoking invitation") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Invitation revoked successfully",
+	})
+}
+
+// AcceptBusinessInvitation godoc
+// @Router /business/invitations/accept [post]
+// @Summary Accept an invitation
+// @Description Joins the business with the code from the invitation email. The caller must be logged in with the invited email address.
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param body body entity.AcceptBusinessInvitationRequest true "Invitation code"
+// @Success 200 {object} entity.BusinessMember
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) AcceptBusinessInvitation(ctx *gin.Context) {
+	var (
+		body entity.AcceptBusinessInvitationRequest
+	)
+
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Token == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.UseCase.BusinessInvitationRepo.GetSingle(ctx, entity.BusinessInvitation{
+		TokenHash: hash.HashToken(strings.TrimSpace(body.Token)),
+	})
+	if err == pgx.ErrNoRows {
+		h.ReturnError(ctx, config.ErrorNotFound, "Invitation not found", http.StatusNotFound)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error getting invitation") {
+		return
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, invitation.ExpiresAt)
+	if invitation.Status != "pending" || err != nil || time.Now().After(expiresAt) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invitation is expired or no longer valid", http.StatusBadRequest)
+		return
+	}
+
+	if !strings.EqualFold(invitation.Email, ctx.GetHeader("email")) {
+		h.forbidden(ctx, "This invitation was sent to another email address")
+		return
+	}
+
+	userID := ctx.GetHeader("sub")
+	_, err = h.UseCase.BusinessMemberRepo.GetSingle(ctx, entity.BusinessMember{BusinessID: invitation.BusinessID, UserID: userID})
+	if err == nil {
+		h.ReturnError(ctx, config.ErrorConflict, "You are already a member of this business", http.StatusBadRequest)
+		return
+	}
+	if err != pgx.ErrNoRows {
+		h.HandleDbError(ctx, err, "Error getting business member")
+		return
+	}
+
+	member, err := h.UseCase.BusinessInvitationRepo.Accept(ctx, invitation, userID)
+	if err == pgx.ErrNoRows {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invitation is expired or no longer valid", http.StatusBadRequest)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error accepting invitation") {
+		return
+	}
+
+	// same as creating a business, members manage businesses
+	if ctx.GetHeader("user_type") != "businessman" {
+		_, err = h.UseCase.UserRepo.Update(ctx, entity.User{ID: userID, UserType: "businessman"})
+		if h.HandleDbError(ctx, err, "Error update type user in business") {
+			return
+		}
+	}
+
+	ctx.JSON(200, member)
+}
@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Categories form a tree, businesses are listed under any number of them and
+// show up when filtering by the category or any category above it. Everyone
+// can read the tree, only admins change it.
+
+var (
+	categorySlugRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	languageCodeRegexp = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// GetCategory godoc
+// @Router /category/{id} [get]
+// @Summary Get a category
+// @Description Get a category by its ID or slug
+// @Security BearerAuth
+// @Tags category
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID or slug"
+// @Success 200 {object} entity.Category
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetCategory(ctx *gin.Context) {
+	category, err := h.UseCase.CategoryRepo.GetSingle(ctx, categoryKey(ctx.Param("id")))
+	if h.HandleDbError(ctx, err, "Error getting category") {
+		return
+	}
+
+	ctx.JSON(200, category)
+}
+
+// GetCategories godoc
+// @Router /category/list [get]
+// @Summary Get a list of categories
+// @Description Lists categories by slug, every category has the id of its parent so clients can build the tree
+// @Security BearerAuth
+// @Tags category
+// @Accept  json
+// @Produce  json
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Param parent_id query string false "Only the direct children of this category"
+// @Success 200 {object} entity.CategoryList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetCategories(ctx *gin.Context) {
+	var (
+		req entity.GetListFilter
+	)
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+
+	if parentID := ctx.Query("parent_id"); parentID != "" {
+		if _, err := uuid.Parse(parentID); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "parent_id must be a category id", http.StatusBadRequest)
+			return
+		}
+		req.Filters = append(req.Filters, entity.Filter{
+			Column: "parent_id",
+			Type:   "eq",
+			Value:  parentID,
+		})
+	}
+
+	req.OrderBy = append(req.OrderBy, entity.OrderBy{
+		Column: "slug",
+		Order:  "asc",
+	})
+
+	categories, err := h.UseCase.CategoryRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting categories") {
+		return
+	}
+
+	ctx.JSON(200, categories)
+}
+
+// CreateCategory godoc
+// @Router /admin/categories [post]
+// @Summary Create a category
+// @Description The slug is lower case words joined by dashes, names are keyed by two letter language codes and need at least "en". Leave parent_id empty for a top level category.
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param body body entity.Category true "Category"
+// @Success 200 {object} entity.Category
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) CreateCategory(ctx *gin.Context) {
+	var (
+		body entity.Category
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.validCategory(ctx, &body) {
+		return
+	}
+
+	category, err := h.UseCase.CategoryRepo.Create(ctx, body)
+	if h.HandleDbError(ctx, err, "Error creating category") {
+		return
+	}
+
+	ctx.JSON(200, category)
+}
+
+// UpdateCategory godoc
+// @Router /admin/categories/{id} [put]
+// @Summary Update a category
+// @Description Replaces the slug, parent and names of a category. Moving a category moves everything below it.
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Param body body entity.Category true "Category"
+// @Success 200 {object} entity.Category
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) UpdateCategory(ctx *gin.Context) {
+	var (
+		body entity.Category
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	body.ID = ctx.Param("id")
+
+	if _, err = uuid.Parse(body.ID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+
+	if !h.validCategory(ctx, &body) {
+		return
+	}
+
+	// a category can't move below itself
+	if body.ParentID != "" {
+		subtree, err := h.UseCase.CategoryRepo.GetSubtreeIDs(ctx, entity.Id{ID: body.ID})
+		if h.HandleDbError(ctx, err, "Error getting category subtree") {
+			return
+		}
+		for _, id := range subtree {
+			if id == body.ParentID {
+				h.ReturnError(ctx, config.ErrorBadRequest, "A category can't be moved below itself", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	category, err := h.UseCase.CategoryRepo.Update(ctx, body)
+	if h.HandleDbError(ctx, err, "Error updating category") {
+		return
+	}
+
+	ctx.JSON(200, category)
+}
+
+// DeleteCategory godoc
+// @Router /admin/categories/{id} [delete]
+// @Summary Delete a category
+// @Description Only categories without businesses and subcategories can be deleted
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) DeleteCategory(ctx *gin.Context) {
+	var (
+		req entity.Id
+	)
+
+	req.ID = ctx.Param("id")
+	if _, err := uuid.Parse(req.ID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+
+	err := h.UseCase.CategoryRepo.Delete(ctx, req)
+	if h.HandleDbError(ctx, err, "Error deleting category") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Category deleted successfully",
+	})
+}
+
+// validCategory checks the slug, names and parent of a category that is
+// about to be saved and trims its names.
+func (h *Handler) validCategory(ctx *gin.Context, category *entity.Category) bool {
+	category.Slug = strings.TrimSpace(category.Slug)
+	if len(category.Slug) > 64 || !categorySlugRegexp.MatchString(category.Slug) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "slug must be lower case letters and digits joined by dashes, like \"coffee-shops\"", http.StatusBadRequest)
+		return false
+	}
+
+	names := make(map[string]string, len(category.Names))
+	for lang, name := range category.Names {
+		name = strings.TrimSpace(name)
+		if !languageCodeRegexp.MatchString(lang) || name == "" {
+			h.ReturnError(ctx, config.ErrorBadRequest, "names must map two letter language codes to non-empty names", http.StatusBadRequest)
+			return false
+		}
+		names[lang] = name
+	}
+	if names["en"] == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "names must have an english (\"en\") name", http.StatusBadRequest)
+		return false
+	}
+	category.Names = names
+
+	if category.ParentID != "" {
+		if category.ParentID == category.ID {
+			h.ReturnError(ctx, config.ErrorBadRequest, "A category can't be its own parent", http.StatusBadRequest)
+			return false
+		}
+		if _, err := uuid.Parse(category.ParentID); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Unknown parent category", http.StatusBadRequest)
+			return false
+		}
+		_, err := h.UseCase.CategoryRepo.GetSingle(ctx, entity.Category{ID: category.ParentID})
+		if err == pgx.ErrNoRows {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Unknown parent category", http.StatusBadRequest)
+			return false
+		}
+		if h.HandleDbError(ctx, err, "Error getting parent category") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validBusinessCategories checks that every id in business.CategoryIDs is a
+// category and drops duplicates.
+func (h *Handler) validBusinessCategories(ctx *gin.Context, business *entity.Business) bool {
+	if business.CategoryIDs == nil {
+		return true
+	}
+
+	ids := make([]string, 0, len(business.CategoryIDs))
+	seen := make(map[string]bool, len(business.CategoryIDs))
+	for _, id := range business.CategoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > config.BusinessMaxCategories {
+		h.ReturnError(ctx, config.ErrorBadRequest, "A business can have at most "+strconv.Itoa(config.BusinessMaxCategories)+" categories", http.StatusBadRequest)
+		return false
+	}
+
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Unknown category "+strconv.Quote(id), http.StatusBadRequest)
+			return false
+		}
+		_, err := h.UseCase.CategoryRepo.GetSingle(ctx, entity.Category{ID: id})
+		if err == pgx.ErrNoRows {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Unknown category "+strconv.Quote(id), http.StatusBadRequest)
+			return false
+		}
+		if h.HandleDbError(ctx, err, "Error getting category") {
+			return false
+		}
+	}
+
+	business.CategoryIDs = ids
+	return true
+}
+
+// categoryKey looks a category up by id when key is one and by slug otherwise.
+func categoryKey(key string) entity.Category {
+	if _, err := uuid.Parse(key); err == nil {
+		return entity.Category{ID: key}
+	}
+	return entity.Category{Slug: key}
+}
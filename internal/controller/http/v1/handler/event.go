@@ -21,6 +21,7 @@ import (
 // @Param event body entity.Event true "Event object"
 // @Success 200 {object} entity.Event
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) CreateEvent(ctx *gin.Context) {
 	var req entity.Event
 
@@ -34,6 +35,10 @@ func (h *Handler) CreateEvent(ctx *gin.Context) {
 		return
 	}
 
+	if !h.authorizeBusiness(ctx, req.BusinessID, "editor") {
+		return
+	}
+
 	res, err := h.UseCase.EventRepo.Create(ctx, req)
 	if h.HandleDbError(ctx, err, "Error creating event") {
 		return
@@ -123,6 +128,7 @@ func (h *Handler) GetEvents(ctx *gin.Context) {
 // @Param event body entity.Event true "Event object"
 // @Success 200 {object} entity.Event
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) UpdateEvent(ctx *gin.Context) {
 	var req entity.Event
 
@@ -136,6 +142,10 @@ func (h *Handler) UpdateEvent(ctx *gin.Context) {
 		return
 	}
 
+	if !h.authorizeEvent(ctx, req.ID) {
+		return
+	}
+
 
 	updatedEvent, err := h.UseCase.EventRepo.Update(ctx, req)
 	if h.HandleDbError(ctx, err, "Error updating event") {
@@ -156,9 +166,14 @@ func (h *Handler) UpdateEvent(ctx *gin.Context) {
 // @Param id path string true "Event ID"
 // @Success 200 {object} entity.SuccessResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) DeleteEvent(ctx *gin.Context) {
 	id := ctx.Param("id")
 
+	if !h.authorizeEvent(ctx, id) {
+		return
+	}
+
 	err := h.UseCase.EventRepo.Delete(ctx, entity.Id{ID: id})
 	if h.HandleDbError(ctx, err, "Error deleting event") {
 		return
@@ -209,6 +224,7 @@ func (h *Handler) AddParticipant(ctx *gin.Context) {
 // @Param participant body entity.EventParticipant true "Participant object"
 // @Success 200 {object} entity.SuccessResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) RemoveParticipant(ctx *gin.Context) {
 	var req entity.EventParticipant
 
@@ -217,6 +233,14 @@ func (h *Handler) RemoveParticipant(ctx *gin.Context) {
 		return
 	}
 
+	// participants can leave on their own, removing someone else is up to the organiser
+	if req.UserID == "" {
+		req.UserID = ctx.GetHeader("sub")
+	}
+	if req.UserID != ctx.GetHeader("sub") && !h.authorizeEvent(ctx, req.EventID) {
+		return
+	}
+
 	err = h.UseCase.EventRepo.RemoveParticipant(ctx, req)
 	if h.HandleDbError(ctx, err, "Error removing participant") {
 		return
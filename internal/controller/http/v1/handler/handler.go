@@ -4,7 +4,12 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/usecase"
 	minio "github.com/Avazbek-02/udevslab-lesson6/pkg/MinIO"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/geo"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/jwt"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/oidc"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/moderation"
+	"github.com/casbin/casbin"
 	rediscache "github.com/golanguzb70/redis-cache"
 )
 
@@ -14,14 +19,35 @@ type Handler struct {
 	UseCase *usecase.UseCase
 	Redis   rediscache.RedisCache
 	MinIO   *minio.MinIO
+	JWTKeys *jwt.KeySet
+	OIDC    *oidc.Provider // nil when social login is not configured
+	Enforcer *casbin.SyncedEnforcer
+	Gazetteer *geo.Gazetteer // nil when geocoding is not configured
+	Screener *moderation.Screener
 }
 
-func NewHandler(l *logger.Logger, c *config.Config, useCase *usecase.UseCase, redis rediscache.RedisCache, minio *minio.MinIO) *Handler {
-	return &Handler{
+func NewHandler(l *logger.Logger, c *config.Config, useCase *usecase.UseCase, redis rediscache.RedisCache, minio *minio.MinIO, jwtKeys *jwt.KeySet, enforcer *casbin.SyncedEnforcer, gazetteer *geo.Gazetteer) *Handler {
+	handler := &Handler{
 		Logger:  l,
 		Config:  c,
 		UseCase: useCase,
 		Redis:   redis,
 		MinIO: minio,
+		JWTKeys: jwtKeys,
+		Enforcer: enforcer,
+		Gazetteer: gazetteer,
+		Screener: moderation.New(c.Moderation.ModerationBannedWords),
 	}
+
+	if c.OIDC.OidcIssuer != "" {
+		handler.OIDC = oidc.New(oidc.Config{
+			Issuer:       c.OIDC.OidcIssuer,
+			ClientID:     c.OIDC.OidcClientID,
+			ClientSecret: c.OIDC.OidcClientSecret,
+			RedirectURL:  c.OIDC.OidcRedirectURL,
+			Scopes:       c.OIDC.OidcScopes,
+		})
+	}
+
+	return handler
 }
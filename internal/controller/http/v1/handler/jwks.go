@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS serves the public keys access tokens are signed with, so other
+// services can verify them without holding a secret. It is mounted outside
+// /v1 at the standard /.well-known/jwks.json path.
+func (h *Handler) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(200, h.JWTKeys.JWKS())
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// loginLocked writes an error and returns true while the account is locked
+// after too many wrong passwords.
+func (h *Handler) loginLocked(ctx *gin.Context, user entity.User, platform string) bool {
+	if _, err := h.Redis.Get(ctx, "login-lock-"+user.ID); err != nil {
+		return false
+	}
+
+	h.recordLoginFailure(ctx, user, user.Email, platform, "locked")
+	h.ReturnError(ctx, config.ErrorAccountLocked, "Too many failed login attempts, please try again later or reset your password", http.StatusTooManyRequests)
+	return true
+}
+
+// loginFailed records a wrong password and locks the account once
+// config.LoginMaxFailures is reached.
+func (h *Handler) loginFailed(ctx *gin.Context, user entity.User, platform string) {
+	h.recordLoginFailure(ctx, user, user.Email, platform, "invalid_password")
+
+	key := "login-failures-" + user.ID
+	failures, err := h.failedAttempt(ctx, key, config.LoginFailureWindow)
+	if err != nil {
+		h.Logger.Error(err, "Error counting failed logins")
+		return
+	}
+
+	if failures < config.LoginMaxFailures {
+		return
+	}
+
+	err = h.Redis.Set(ctx, "login-lock-"+user.ID, "1", int(config.LoginLockoutTime.Seconds()))
+	if err != nil {
+		h.Logger.Error(err, "Error locking account")
+		return
+	}
+	_ = h.UseCase.AttemptRepo.Reset(ctx, key)
+
+	h.notifyUser(ctx, user, fmt.Sprintf("Your account was locked for %d minutes after %d failed login attempts. If this wasn't you, please reset your password.",
+		int(config.LoginLockoutTime.Minutes()), failures))
+}
+
+func (h *Handler) recordLoginFailure(ctx *gin.Context, user entity.User, email, platform, reason string) {
+	_, err := h.UseCase.LoginEventRepo.Create(ctx, entity.LoginEvent{
+		UserID:        user.ID,
+		Email:         email,
+		Success:       false,
+		FailureReason: reason,
+		IPAddress:     ctx.ClientIP(),
+		UserAgent:     ctx.Request.UserAgent(),
+		Platform:      platform,
+	})
+	if err != nil {
+		h.Logger.Error(err, "Error recording login event")
+	}
+}
+
+// loginSucceeded records the login and tells the user when it came from an IP
+// address and user agent they haven't logged in from before.
+func (h *Handler) loginSucceeded(ctx *gin.Context, user entity.User, platform string) {
+	if err := h.UseCase.AttemptRepo.Reset(ctx, "login-failures-"+user.ID); err != nil {
+		h.Logger.Error(err, "Error resetting failed logins")
+	}
+
+	event := entity.LoginEvent{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Success:   true,
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Platform:  platform,
+	}
+
+	known, firstLogin, err := h.UseCase.LoginEventRepo.IsKnownDevice(ctx, event)
+	if err != nil {
+		h.Logger.Error(err, "Error checking login device")
+	}
+
+	_, err = h.UseCase.LoginEventRepo.Create(ctx, event)
+	if err != nil {
+		h.Logger.Error(err, "Error recording login event")
+	}
+
+	if !known && !firstLogin {
+		h.notifyUser(ctx, user, fmt.Sprintf("New login to your account from %s (%s). If this wasn't you, log out that device and change your password.",
+			event.IPAddress, event.UserAgent))
+	}
+}
+
+func (h *Handler) notifyUser(ctx *gin.Context, user entity.User, message string) {
+	_, err := h.UseCase.NotificationRepo.Create(ctx, entity.Notification{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Message: message,
+		Status:  "unread",
+	})
+	if err != nil {
+		h.Logger.Error(err, "Error creating notification")
+	}
+}
+
+// GetLoginEvents godoc
+// @Router /user/login-events [get]
+// @Summary Get my login history
+// @Description Lists successful and failed logins to the caller's account, newest first
+// @Security BearerAuth
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.LoginEventList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetLoginEvents(ctx *gin.Context) {
+	h.getLoginEvents(ctx, ctx.GetHeader("sub"))
+}
+
+// GetUserLoginEvents godoc
+// @Router /admin/users/{id}/login-events [get]
+// @Summary Get a user's login history
+// @Description Lists successful and failed logins to a user's account, newest first
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.LoginEventList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetUserLoginEvents(ctx *gin.Context) {
+	h.getLoginEvents(ctx, ctx.Param("id"))
+}
+
+func (h *Handler) getLoginEvents(ctx *gin.Context, userID string) {
+	var (
+		req entity.GetListFilter
+	)
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "user_id",
+		Type:   "eq",
+		Value:  userID,
+	})
+
+	req.OrderBy = append(req.OrderBy, entity.OrderBy{
+		Column: "created_at",
+		Order:  "desc",
+	})
+
+	events, err := h.UseCase.LoginEventRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting login events") {
+		return
+	}
+
+	ctx.JSON(200, events)
+}
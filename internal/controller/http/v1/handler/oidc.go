@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/etc"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/hash"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// OidcLogin godoc
+// @Router /auth/oidc/login [get]
+// @Summary Start social login
+// @Description Returns the URL of the identity provider's login page. After logging in there the user is sent back to /auth/oidc/callback.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param platform query string false "Platform (web, mobile, admin), web by default"
+// @Success 200 {object} entity.OidcLoginResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) OidcLogin(ctx *gin.Context) {
+	if h.OIDC == nil {
+		h.ReturnError(ctx, config.ErrorNotFound, "Social login is not configured", http.StatusNotFound)
+		return
+	}
+
+	platform := ctx.DefaultQuery("platform", "web")
+	if platform != "web" && platform != "mobile" && platform != "admin" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	loginState := entity.OidcLoginState{Platform: platform}
+	if loginState.Nonce, err = oidc.RandomString(); err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+	if loginState.CodeVerifier, err = oidc.RandomString(); err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	authURL, err := h.OIDC.AuthCodeURL(ctx, state, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		h.Logger.Error(err, "oidc")
+		h.ReturnError(ctx, config.ErrorInternalServer, "Identity provider is unavailable", http.StatusBadGateway)
+		return
+	}
+
+	js, err := json.Marshal(loginState)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.Redis.Set(ctx, "oidc-state-"+state, string(js), int(config.OidcStateExpireTime.Seconds()))
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(200, entity.OidcLoginResponse{
+		AuthorizationURL: authURL,
+	})
+}
+
+// OidcCallback godoc
+// @Router /auth/oidc/callback [get]
+// @Summary Finish social login
+// @Description The identity provider redirects here. The provider account is linked to the user with the same verified email, or a new user is created. Responds like /auth/login.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) OidcCallback(ctx *gin.Context) {
+	if h.OIDC == nil {
+		h.ReturnError(ctx, config.ErrorNotFound, "Social login is not configured", http.StatusNotFound)
+		return
+	}
+
+	if providerError := ctx.Query("error"); providerError != "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Login was cancelled: "+providerError, http.StatusBadRequest)
+		return
+	}
+
+	state, code := ctx.Query("state"), ctx.Query("code")
+	if state == "" || code == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	// the state is single use, a replayed callback finds nothing
+	key := "oidc-state-" + state
+	js, err := h.Redis.Get(ctx, key)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Login request is expired or invalid, please try again", http.StatusBadRequest)
+		return
+	}
+	if err = h.Redis.Del(ctx, key); err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	loginState := entity.OidcLoginState{}
+	if err = json.Unmarshal([]byte(js), &loginState); err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := h.OIDC.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		h.Logger.Error(err, "oidc")
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Login with the identity provider failed", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := h.OIDC.VerifyIDToken(ctx, token.IDToken, loginState.Nonce)
+	if err != nil {
+		h.Logger.Error(err, "oidc")
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Login with the identity provider failed", http.StatusUnauthorized)
+		return
+	}
+
+	user, ok := h.oidcUser(ctx, claims)
+	if !ok {
+		return
+	}
+
+	if !h.checkPlatform(ctx, user, loginState.Platform) {
+		return
+	}
+
+	if !h.checkUserStatus(ctx, user.Status) {
+		return
+	}
+
+	h.completeLogin(ctx, user, loginState.Platform)
+}
+
+// oidcUser returns the user the provider account belongs to. Accounts are
+// linked by email on first login, which is only safe when the provider has
+// verified the email. Users that don't exist yet are created.
+func (h *Handler) oidcUser(ctx *gin.Context, claims oidc.Claims) (entity.User, bool) {
+	identity, err := h.UseCase.IdentityRepo.GetSingle(ctx, entity.UserIdentity{
+		Issuer:  h.OIDC.Issuer(),
+		Subject: claims.Subject,
+	})
+	if err == nil {
+		user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: identity.UserID})
+		if h.HandleDbError(ctx, err, "Error getting user") {
+			return entity.User{}, false
+		}
+		return user, true
+	}
+	if err != pgx.ErrNoRows {
+		h.HandleDbError(ctx, err, "Error getting identity")
+		return entity.User{}, false
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		h.ReturnError(ctx, config.ErrorNotVerified, "The identity provider has not verified your email address", http.StatusBadRequest)
+		return entity.User{}, false
+	}
+
+	// nobody knows this password, the user can set one through forgot-password
+	password, err := etc.GenerateToken(32)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return entity.User{}, false
+	}
+	password, err = hash.HashPassword(password)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return entity.User{}, false
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{Email: claims.Email})
+	switch {
+	case err == pgx.ErrNoRows:
+		username, _, _ := strings.Cut(claims.Email, "@")
+		fullName := claims.Name
+		if fullName == "" {
+			fullName = username
+		}
+
+		user, err = h.UseCase.UserRepo.Create(ctx, entity.User{
+			FullName: fullName,
+			UserType: "user",
+			UserRole: "user",
+			Username: username,
+			Email:    claims.Email,
+			Status:   "active",
+			Password: password,
+			AvatarId: claims.Email,
+		})
+		if h.HandleDbError(ctx, err, "Error creating user") {
+			return entity.User{}, false
+		}
+	case err != nil:
+		h.HandleDbError(ctx, err, "Error getting user")
+		return entity.User{}, false
+	case user.Status == "inverify":
+		// whoever registered this account never proved they own the email, the
+		// provider just did, so their password must not keep working
+		user, err = h.UseCase.UserRepo.Update(ctx, entity.User{
+			ID:       user.ID,
+			Status:   "active",
+			Password: password,
+		})
+		if h.HandleDbError(ctx, err, "Error updating user") {
+			return entity.User{}, false
+		}
+	}
+
+	_, err = h.UseCase.IdentityRepo.Create(ctx, entity.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  h.OIDC.Issuer(),
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	})
+	if h.HandleDbError(ctx, err, "Error linking identity") {
+		return entity.User{}, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/etc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errOtpExpired          = errors.New("otp is expired or was never sent")
+	errOtpInvalid          = errors.New("incorrect otp")
+	errOtpAttemptsExceeded = errors.New("too many incorrect otp attempts")
+	errOtpCooldown         = errors.New("otp was sent recently")
+)
+
+// sendOtp stores a new code under key and emails it to the user. A code can't be
+// resent for the same key until config.OtpResendCooldown has passed.
+func (h *Handler) sendOtp(ctx *gin.Context, key, email string, emailBody func(otp string) (string, error)) error {
+	cooldownKey := key + "-cooldown"
+	if _, err := h.Redis.Get(ctx, cooldownKey); err == nil {
+		return errOtpCooldown
+	}
+
+	otp := etc.GenerateOTP(6)
+	err := h.Redis.Set(ctx, key, otp, int(config.OtpExpireTime.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	// a fresh code gets a fresh set of attempts
+	err = h.UseCase.AttemptRepo.Reset(ctx, key+"-attempts")
+	if err != nil {
+		return err
+	}
+
+	err = h.Redis.Set(ctx, cooldownKey, "1", int(config.OtpResendCooldown.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	body, err := emailBody(otp)
+	if err != nil {
+		return err
+	}
+
+	return etc.SendEmail(h.Config.Gmail.Host, h.Config.Gmail.Port, h.Config.Gmail.Email, h.Config.Gmail.EmailPass, email, body)
+}
+
+// checkOtp compares code with the one stored under key. Wrong guesses are
+// counted and after config.OtpMaxAttempts the code is thrown away, so it can't
+// be brute-forced during its lifetime. A matching code is consumed.
+func (h *Handler) checkOtp(ctx *gin.Context, key, code string) error {
+	attemptsKey := key + "-attempts"
+
+	otp, err := h.Redis.Get(ctx, key)
+	if err != nil {
+		return errOtpExpired
+	}
+
+	if code != "" && subtle.ConstantTimeCompare([]byte(otp), []byte(code)) == 1 {
+		if err = h.Redis.Del(ctx, key); err != nil {
+			return err
+		}
+		return h.UseCase.AttemptRepo.Reset(ctx, attemptsKey)
+	}
+
+	attempts, err := h.failedAttempt(ctx, attemptsKey, config.OtpExpireTime)
+	if err != nil {
+		return err
+	}
+
+	if attempts >= config.OtpMaxAttempts {
+		if err = h.Redis.Del(ctx, key); err != nil {
+			return err
+		}
+		if err = h.UseCase.AttemptRepo.Reset(ctx, attemptsKey); err != nil {
+			return err
+		}
+		return errOtpAttemptsExceeded
+	}
+
+	return errOtpInvalid
+}
+
+// failedAttempt increments the counter stored under key and returns the new value.
+// The counter is forgotten ttl after the last failure.
+func (h *Handler) failedAttempt(ctx *gin.Context, key string, ttl time.Duration) (int, error) {
+	return h.UseCase.AttemptRepo.Increment(ctx, key, ttl)
+}
+
+// ReturnOtpError writes the API error for an error returned by sendOtp or checkOtp.
+func (h *Handler) ReturnOtpError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errOtpInvalid):
+		h.ReturnError(ctx, config.ErrorInvalidOtp, "Incorrect otp", http.StatusBadRequest)
+	case errors.Is(err, errOtpExpired):
+		h.ReturnError(ctx, config.ErrorOtpExpired, "Otp is expired, please request a new one", http.StatusBadRequest)
+	case errors.Is(err, errOtpAttemptsExceeded):
+		h.ReturnError(ctx, config.ErrorTooManyRequest, "Too many incorrect attempts, please request a new otp", http.StatusTooManyRequests)
+	case errors.Is(err, errOtpCooldown):
+		h.ReturnError(ctx, config.ErrorTooManyRequest, "Otp was sent recently, please wait before requesting a new one", http.StatusTooManyRequests)
+	default:
+		h.Logger.Error(err, "otp")
+		h.ReturnError(ctx, config.ErrorInternalServer, "Error sending OTP", http.StatusInternalServerError)
+	}
+}
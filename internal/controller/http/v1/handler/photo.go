@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	minio "github.com/Avazbek-02/udevslab-lesson6/pkg/MinIO"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Businesses, reviews and users have photo galleries. The cover photo of a
+// gallery is what the photos field of a business or review and the
+// profile_picture of a user show.
+
+// AddBusinessPhoto godoc
+// @Router /business/{id}/photos [post]
+// @Summary Add a photo to a business
+// @Description Uploads a photo to the end of the gallery. The first photo becomes the cover.
+// @Security BearerAuth
+// @Tags business
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Business ID"
+// @Param file formData file true "Image file"
+// @Param caption formData string false "Caption"
+// @Param is_cover formData boolean false "Make the photo the cover"
+// @Success 200 {object} entity.Photo
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) AddBusinessPhoto(ctx *gin.Context) {
+	businessID := ctx.Param("id")
+	if !h.authorizeBusiness(ctx, businessID, "editor") {
+		return
+	}
+
+	photo, ok := h.uploadPhoto(ctx, "business", businessID, ctx.PostForm("is_cover") == "true")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(200, photo)
+}
+
+// GetBusinessPhotos godoc
+// @Router /business/{id}/photos [get]
+// @Summary Get the photos of a business
+// @Description The cover comes first, then the gallery in order
+// @Security BearerAuth
+// @Tags business
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Business ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.PhotoList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetBusinessPhotos(ctx *gin.Context) {
+	h.getPhotos(ctx, "business_id")
+}
+
+// AddReviewPhoto godoc
+// @Router /review/{id}/photos [post]
+// @Summary Add a photo to a review
+// @Description Uploads a photo to the end of the review's gallery. The first photo becomes the cover.
+// @Security BearerAuth
+// @Tags review
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Review ID"
+// @Param file formData file true "Image file"
+// @Param caption formData string false "Caption"
+// @Param is_cover formData boolean false "Make the photo the cover"
+// @Success 200 {object} entity.Photo
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) AddReviewPhoto(ctx *gin.Context) {
+	reviewID := ctx.Param("id")
+	if !h.authorizeReview(ctx, reviewID) {
+		return
+	}
+
+	photo, ok := h.uploadPhoto(ctx, "review", reviewID, ctx.PostForm("is_cover") == "true")
+	if !ok {
+		return
+	}
+
+	ctx.JSON(200, photo)
+}
+
+// GetReviewPhotos godoc
+// @Router /review/{id}/photos [get]
+// @Summary Get the photos of a review
+// @Description The cover comes first, then the gallery in order
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.PhotoList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetReviewPhotos(ctx *gin.Context) {
+	h.getPhotos(ctx, "review_id")
+}
+
+// GetUserPhotos godoc
+// @Router /user/{id}/photos [get]
+// @Summary Get the avatars of a user
+// @Description The current avatar comes first, then the earlier ones in order
+// @Security BearerAuth
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.PhotoList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetUserPhotos(ctx *gin.Context) {
+	h.getPhotos(ctx, "user_id")
+}
+
+// GetPhoto godoc
+// @Router /photo/{id} [get]
+// @Summary Get a photo
+// @Description Get a photo by ID
+// @Security BearerAuth
+// @Tags photo
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Photo ID"
+// @Success 200 {object} entity.Photo
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetPhoto(ctx *gin.Context) {
+	if _, err := uuid.Parse(ctx.Param("id")); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid photo id", http.StatusBadRequest)
+		return
+	}
+
+	photo, err := h.UseCase.PhotoRepo.GetSingle(ctx, entity.Id{ID: ctx.Param("id")})
+	if h.HandleDbError(ctx, err, "Error getting photo") {
+		return
+	}
+
+	ctx.JSON(200, photo)
+}
+
+// UpdatePhoto godoc
+// @Router /photo/{id} [put]
+// @Summary Update a photo
+// @Description Changes the caption or position of a photo or makes it the cover. Only the fields given change.
+// @Security BearerAuth
+// @Tags photo
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Photo ID"
+// @Param body body entity.PhotoUpdateRequest true "Changes"
+// @Success 200 {object} entity.Photo
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) UpdatePhoto(ctx *gin.Context) {
+	var (
+		body entity.PhotoUpdateRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err = uuid.Parse(ctx.Param("id")); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid photo id", http.StatusBadRequest)
+		return
+	}
+
+	photo, err := h.UseCase.PhotoRepo.GetSingle(ctx, entity.Id{ID: ctx.Param("id")})
+	if h.HandleDbError(ctx, err, "Error getting photo") {
+		return
+	}
+
+	if !h.authorizePhoto(ctx, photo) {
+		return
+	}
+
+	if body.Caption != nil {
+		photo.Caption = strings.TrimSpace(*body.Caption)
+	}
+	if body.Position != nil {
+		if *body.Position < 0 {
+			h.ReturnError(ctx, config.ErrorBadRequest, "position can't be negative", http.StatusBadRequest)
+			return
+		}
+		photo.Position = *body.Position
+	}
+	if body.IsCover != nil {
+		if !*body.IsCover && photo.IsCover {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Make another photo the cover instead", http.StatusBadRequest)
+			return
+		}
+		photo.IsCover = *body.IsCover
+	}
+
+	photo, err = h.UseCase.PhotoRepo.Update(ctx, photo)
+	if h.HandleDbError(ctx, err, "Error updating photo") {
+		return
+	}
+
+	ctx.JSON(200, photo)
+}
+
+// DeletePhoto godoc
+// @Router /photo/{id} [delete]
+// @Summary Delete a photo
+// @Description Removes the photo from its gallery and from storage. The next photo becomes the cover when the cover is deleted.
+// @Security BearerAuth
+// @Tags photo
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Photo ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) DeletePhoto(ctx *gin.Context) {
+	if _, err := uuid.Parse(ctx.Param("id")); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid photo id", http.StatusBadRequest)
+		return
+	}
+
+	photo, err := h.UseCase.PhotoRepo.GetSingle(ctx, entity.Id{ID: ctx.Param("id")})
+	if h.HandleDbError(ctx, err, "Error getting photo") {
+		return
+	}
+
+	if !h.authorizePhoto(ctx, photo) {
+		return
+	}
+
+	photo, err = h.UseCase.PhotoRepo.Delete(ctx, entity.Id{ID: photo.ID})
+	if h.HandleDbError(ctx, err, "Error deleting photo") {
+		return
+	}
+
+	h.deletePhotoObjects(photo.ObjectKey)
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Photo deleted successfully",
+	})
+}
+
+// authorizePhoto allows whoever may change the photo's owner.
+func (h *Handler) authorizePhoto(ctx *gin.Context, photo entity.Photo) bool {
+	switch photo.OwnerType {
+	case "business":
+		return h.authorizeBusiness(ctx, photo.OwnerID, "editor")
+	case "review":
+		return h.authorizeReview(ctx, photo.OwnerID)
+	default:
+		if photo.OwnerID != ctx.GetHeader("sub") && !isAdmin(ctx) {
+			return h.forbidden(ctx, "You can only change your own photos")
+		}
+		return true
+	}
+}
+
+// uploadPhoto stores the image in the "file" form field and adds it to the
+// gallery of the owner, with the caption from the "caption" form field.
+func (h *Handler) uploadPhoto(ctx *gin.Context, ownerType, ownerID string, cover bool) (entity.Photo, bool) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Error getting file", http.StatusBadRequest)
+		return entity.Photo{}, false
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if _, ok := minio.ContentType[ext]; !ok {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Only images can be uploaded", http.StatusBadRequest)
+		return entity.Photo{}, false
+	}
+	if file.Size > config.PhotoMaxSize {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Photos can be at most "+strconv.FormatInt(config.PhotoMaxSize>>20, 10)+" MB", http.StatusBadRequest)
+		return entity.Photo{}, false
+	}
+
+	objectKey := uuid.NewString() + ext
+	tempPath := filepath.Join(os.TempDir(), objectKey)
+	if err := ctx.SaveUploadedFile(file, tempPath); err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Error saving file", http.StatusInternalServerError)
+		return entity.Photo{}, false
+	}
+	defer os.Remove(tempPath)
+
+	url, err := h.MinIO.Upload(objectKey, tempPath)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Error uploading file", http.StatusInternalServerError)
+		return entity.Photo{}, false
+	}
+
+	photo, err := h.UseCase.PhotoRepo.Create(ctx, entity.Photo{
+		OwnerType:  ownerType,
+		OwnerID:    ownerID,
+		URL:        url,
+		ObjectKey:  objectKey,
+		Caption:    strings.TrimSpace(ctx.PostForm("caption")),
+		UploadedBy: ctx.GetHeader("sub"),
+		IsCover:    cover,
+	})
+	if err != nil {
+		h.deletePhotoObjects(objectKey)
+	}
+	if h.HandleDbError(ctx, err, "Error saving photo") {
+		return entity.Photo{}, false
+	}
+
+	return photo, true
+}
+
+func (h *Handler) getPhotos(ctx *gin.Context, ownerColumn string) {
+	var (
+		req entity.GetListFilter
+	)
+
+	ownerID := ctx.Param("id")
+	if _, err := uuid.Parse(ownerID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: ownerColumn,
+		Type:   "eq",
+		Value:  ownerID,
+	})
+
+	req.OrderBy = append(req.OrderBy,
+		entity.OrderBy{Column: "is_cover", Order: "desc"},
+		entity.OrderBy{Column: "position", Order: "asc"},
+		entity.OrderBy{Column: "created_at", Order: "asc"},
+	)
+
+	photos, err := h.UseCase.PhotoRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting photos") {
+		return
+	}
+
+	ctx.JSON(200, photos)
+}
+
+// photoObjectKeys returns the objects to remove from the bucket once the
+// owner is deleted, its photos are deleted with it by the database.
+func (h *Handler) photoObjectKeys(ctx *gin.Context, ownerType, ownerID string) ([]string, bool) {
+	keys, err := h.UseCase.PhotoRepo.GetObjectKeys(ctx, entity.Photo{OwnerType: ownerType, OwnerID: ownerID})
+	if h.HandleDbError(ctx, err, "Error getting photos") {
+		return nil, false
+	}
+
+	return keys, true
+}
+
+// deletePhotoObjects removes objects from the bucket. A failure only leaves an
+// unused object behind, so it is logged and not returned.
+func (h *Handler) deletePhotoObjects(keys ...string) {
+	for _, key := range keys {
+		if err := h.MinIO.Delete(key); err != nil {
+			h.Logger.Error(err, "Error deleting photo object "+key)
+		}
+	}
+}
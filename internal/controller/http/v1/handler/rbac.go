@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// The enforcer saves every change to postgres through PolicyRepo and tells the
+// other instances to reload through its watcher. The rbac routes have no
+// policy of their own, so only a superadmin can call them.
+
+// GetPolicies godoc
+// @Router /rbac/policies [get]
+// @Summary Get policies
+// @Description Lists the routes each role may call
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Param role query string false "Only policies of this role"
+// @Success 200 {object} entity.PolicyList
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) GetPolicies(ctx *gin.Context) {
+	var rules [][]string
+	if role := ctx.Query("role"); role != "" {
+		rules = h.Enforcer.GetFilteredPolicy(0, role)
+	} else {
+		rules = h.Enforcer.GetPolicy()
+	}
+
+	response := entity.PolicyList{Items: []entity.Policy{}}
+	for _, rule := range rules {
+		if len(rule) < 3 {
+			continue
+		}
+		response.Items = append(response.Items, entity.Policy{
+			Role:    rule[0],
+			Path:    rule[1],
+			Methods: rule[2],
+		})
+	}
+	response.Count = len(response.Items)
+
+	ctx.JSON(200, response)
+}
+
+// AddPolicy godoc
+// @Router /rbac/policies [post]
+// @Summary Add a policy
+// @Description Allows a role to call the routes matching path with the methods matching methods, e.g. "GET|POST"
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Param policy body entity.Policy true "Policy"
+// @Success 200 {object} entity.Policy
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) AddPolicy(ctx *gin.Context) {
+	body, ok := h.bindPolicy(ctx)
+	if !ok {
+		return
+	}
+
+	added, ok := h.changePolicy(ctx, func() bool {
+		return h.Enforcer.AddPolicy(body.Role, body.Path, body.Methods)
+	})
+	if !ok {
+		return
+	}
+
+	if !added {
+		h.ReturnError(ctx, config.ErrorConflict, "Policy already exists", http.StatusBadRequest)
+		return
+	}
+
+	ctx.JSON(200, body)
+}
+
+// RemovePolicy godoc
+// @Router /rbac/policies [delete]
+// @Summary Remove a policy
+// @Description Removes a policy, the role keeps what other policies and its granted roles allow
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Param policy body entity.Policy true "Policy"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RemovePolicy(ctx *gin.Context) {
+	body, ok := h.bindPolicy(ctx)
+	if !ok {
+		return
+	}
+
+	removed, ok := h.changePolicy(ctx, func() bool {
+		return h.Enforcer.RemovePolicy(body.Role, body.Path, body.Methods)
+	})
+	if !ok {
+		return
+	}
+
+	if !removed {
+		h.ReturnError(ctx, config.ErrorNotFound, "Policy not found", http.StatusNotFound)
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Policy removed successfully",
+	})
+}
+
+// GetRoleGrants godoc
+// @Router /rbac/roles [get]
+// @Summary Get role grants
+// @Description Lists which roles inherit the policies of which other roles
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} entity.RoleGrantList
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) GetRoleGrants(ctx *gin.Context) {
+	response := entity.RoleGrantList{Items: []entity.RoleGrant{}}
+	for _, rule := range h.Enforcer.GetGroupingPolicy() {
+		if len(rule) < 2 {
+			continue
+		}
+		response.Items = append(response.Items, entity.RoleGrant{
+			Subject: rule[0],
+			Role:    rule[1],
+		})
+	}
+	response.Count = len(response.Items)
+
+	ctx.JSON(200, response)
+}
+
+// AddRoleGrant godoc
+// @Router /rbac/roles [post]
+// @Summary Grant a role
+// @Description Lets subject do everything role is allowed to do
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Param grant body entity.RoleGrant true "Role grant"
+// @Success 200 {object} entity.RoleGrant
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) AddRoleGrant(ctx *gin.Context) {
+	body, ok := h.bindRoleGrant(ctx)
+	if !ok {
+		return
+	}
+
+	added, ok := h.changePolicy(ctx, func() bool {
+		return h.Enforcer.AddGroupingPolicy(body.Subject, body.Role)
+	})
+	if !ok {
+		return
+	}
+
+	if !added {
+		h.ReturnError(ctx, config.ErrorConflict, "Role is already granted", http.StatusBadRequest)
+		return
+	}
+
+	ctx.JSON(200, body)
+}
+
+// RemoveRoleGrant godoc
+// @Router /rbac/roles [delete]
+// @Summary Revoke a role
+// @Description Stops subject from inheriting the policies of role
+// @Security BearerAuth
+// @Tags rbac
+// @Accept  json
+// @Produce  json
+// @Param grant body entity.RoleGrant true "Role grant"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RemoveRoleGrant(ctx *gin.Context) {
+	body, ok := h.bindRoleGrant(ctx)
+	if !ok {
+		return
+	}
+
+	removed, ok := h.changePolicy(ctx, func() bool {
+		return h.Enforcer.RemoveGroupingPolicy(body.Subject, body.Role)
+	})
+	if !ok {
+		return
+	}
+
+	if !removed {
+		h.ReturnError(ctx, config.ErrorNotFound, "Role grant not found", http.StatusNotFound)
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Role revoked successfully",
+	})
+}
+
+func (h *Handler) bindPolicy(ctx *gin.Context) (entity.Policy, bool) {
+	var body entity.Policy
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return body, false
+	}
+
+	body.Role = strings.TrimSpace(body.Role)
+	body.Path = strings.TrimSpace(body.Path)
+	body.Methods = strings.TrimSpace(body.Methods)
+
+	if body.Role == "" || body.Methods == "" || !strings.HasPrefix(body.Path, "/") {
+		h.ReturnError(ctx, config.ErrorBadRequest, "role, path and methods are required, path must start with /", http.StatusBadRequest)
+		return body, false
+	}
+
+	if _, err := regexp.Compile(body.Methods); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "methods must be a regular expression like GET|POST", http.StatusBadRequest)
+		return body, false
+	}
+
+	return body, true
+}
+
+func (h *Handler) bindRoleGrant(ctx *gin.Context) (entity.RoleGrant, bool) {
+	var body entity.RoleGrant
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return body, false
+	}
+
+	body.Subject = strings.TrimSpace(body.Subject)
+	body.Role = strings.TrimSpace(body.Role)
+
+	if body.Subject == "" || body.Role == "" || body.Subject == body.Role {
+		h.ReturnError(ctx, config.ErrorBadRequest, "subject and role are required and must differ", http.StatusBadRequest)
+		return body, false
+	}
+
+	return body, true
+}
+
+// changePolicy runs a change on the enforcer. Casbin panics when it can't save
+// the change, in that case the enforcer is reloaded so it matches the database again.
+func (h *Handler) changePolicy(ctx *gin.Context, change func() bool) (changed bool, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.Logger.Error(fmt.Errorf("saving policy: %v", r))
+			if err := h.Enforcer.LoadPolicy(); err != nil {
+				h.Logger.Error(err, "Error reloading policy")
+			}
+			h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+			changed, ok = false, false
+		}
+	}()
+
+	return change(), true
+}
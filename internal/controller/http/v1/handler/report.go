@@ -123,6 +123,7 @@ func (h *Handler) GetReports(ctx *gin.Context) {
 // @Param report body entity.Report true "Report object"
 // @Success 200 {object} entity.Report
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) UpdateReport(ctx *gin.Context) {
 	var (
 		body entity.Report
@@ -134,6 +135,10 @@ func (h *Handler) UpdateReport(ctx *gin.Context) {
 		return
 	}
 
+	if !h.authorizeReport(ctx, body.ID) {
+		return
+	}
+
 	report, err := h.UseCase.ReportRepo.Update(ctx, body)
 	if h.HandleDbError(ctx, err, "Error updating report") {
 		return
@@ -153,6 +158,7 @@ func (h *Handler) UpdateReport(ctx *gin.Context) {
 // @Param id path string true "Report ID"
 // @Success 200 {object} entity.SuccessResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) DeleteReport(ctx *gin.Context) {
 	var (
 		req entity.Id
@@ -160,6 +166,10 @@ func (h *Handler) DeleteReport(ctx *gin.Context) {
 
 	req.ID = ctx.Param("id")
 
+	if !h.authorizeReport(ctx, req.ID) {
+		return
+	}
+
 	err := h.UseCase.ReportRepo.Delete(ctx, req)
 	if h.HandleDbError(ctx, err, "Error deleting report") {
 		return
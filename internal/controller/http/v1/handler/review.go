@@ -13,7 +13,7 @@ import (
 // CreatetReview godoc
 // @Router /review [post]
 // @Summary Create a review
-// @Description Create a review
+// @Description A user has one review per business, posting again for the same business updates the existing review. Reviews the pre-screen flags stay pending until an admin approves them.
 // @Security BearerAuth
 // @Tags review
 // @Accept  json
@@ -32,6 +32,12 @@ func (h *Handler) CreateReview(ctx *gin.Context) {
 	}
 	UserId := ctx.GetHeader("sub")
 	req.UserID = UserId
+
+	// the review of this business is replaced, not repeated
+	if !h.screenReview(ctx, &req, entity.Filter{Column: "business_id", Type: "neq", Value: req.BusinessID}) {
+		return
+	}
+
 	res, err := h.UseCase.ReviewRepo.Create(ctx, req)
 	if h.HandleDbError(ctx, err, "Error creating review") {
 		return
@@ -62,7 +68,18 @@ func (h *Handler) GetReview(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, review)
+	// reviews that aren't published are only shown to their author and admins
+	if review.Status != "published" && !isAdmin(ctx) && review.UserID != ctx.GetHeader("sub") {
+		h.ReturnError(ctx, config.ErrorNotFound, "Review not found", http.StatusNotFound)
+		return
+	}
+
+	reviews := []entity.Review{review}
+	if !h.setMyReactions(ctx, reviews) {
+		return
+	}
+
+	ctx.JSON(200, reviews[0])
 }
 
 // GetReviews godoc
@@ -76,6 +93,8 @@ func (h *Handler) GetReview(ctx *gin.Context) {
 // @Param page query number true "page"
 // @Param limit query number true "limit"
 // @Param business_id query string false "business_id"
+// @Param sort query string false "newest (default), helpful for the most useful first, rating_high or rating_low"
+// @Param status query string false "pending, published (default) or rejected, only admins can list other than published"
 // @Success 200 {object} entity.ReviewList
 // @Failure 400 {object} entity.ErrorResponse
 func (h *Handler) GetReviews(ctx *gin.Context) {
@@ -89,18 +108,49 @@ func (h *Handler) GetReviews(ctx *gin.Context) {
 
 	req.Page, _ = strconv.Atoi(page)
 	req.Limit, _ = strconv.Atoi(limit)
-	req.Filters = append(req.Filters,
-		entity.Filter{
-			Column: "business_id",
-			Type:   "eq",
-			Value:  businessID,
-		},
-	)
+	if businessID != "" {
+		req.Filters = append(req.Filters,
+			entity.Filter{
+				Column: "business_id",
+				Type:   "eq",
+				Value:  businessID,
+			},
+		)
+	}
 
-	req.OrderBy = append(req.OrderBy, entity.OrderBy{
-		Column: "created_at",
-		Order:  "desc",
+	status := ctx.DefaultQuery("status", "published")
+	if status != "published" && !isAdmin(ctx) {
+		h.forbidden(ctx, "Only admins can list reviews that aren't published")
+		return
+	}
+	if status != "pending" && status != "published" && status != "rejected" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "status must be pending, published or rejected", http.StatusBadRequest)
+		return
+	}
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "status",
+		Type:   "eq",
+		Value:  status,
 	})
+
+	switch ctx.DefaultQuery("sort", "newest") {
+	case "newest":
+	case "helpful":
+		req.OrderBy = append(req.OrderBy, entity.OrderBy{Column: "useful_count", Order: "desc"})
+	case "rating_high":
+		req.OrderBy = append(req.OrderBy, entity.OrderBy{Column: "rating", Order: "desc"})
+	case "rating_low":
+		req.OrderBy = append(req.OrderBy, entity.OrderBy{Column: "rating", Order: "asc"})
+	default:
+		h.ReturnError(ctx, config.ErrorBadRequest, "sort must be newest, helpful, rating_high or rating_low", http.StatusBadRequest)
+		return
+	}
+
+	// newest first among equals, id keeps the pages stable
+	req.OrderBy = append(req.OrderBy,
+		entity.OrderBy{Column: "created_at", Order: "desc"},
+		entity.OrderBy{Column: "id", Order: "desc"},
+	)
 	if _, err := uuid.Parse(businessID); err != nil && businessID != "" {
 		ctx.JSON(404, gin.H{"Error:": "Wrong format type please write UUID"})
 		return
@@ -111,13 +161,17 @@ func (h *Handler) GetReviews(ctx *gin.Context) {
 		return
 	}
 
+	if !h.setMyReactions(ctx, reviews.Items) {
+		return
+	}
+
 	ctx.JSON(200, reviews)
 }
 
 // UpdateReview godoc
 // @Router /review [put]
 // @Summary Update a review
-// @Description Update a review
+// @Description Update a review, the previous version stays in its history. New feedback goes through the pre-screen again and an edited rejected review waits for an admin.
 // @Security BearerAuth
 // @Tags review
 // @Accept  json
@@ -125,6 +179,7 @@ func (h *Handler) GetReviews(ctx *gin.Context) {
 // @Param review body entity.Review true "Review object"
 // @Success 200 {object} entity.Review
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) UpdateReview(ctx *gin.Context) {
 	var (
 		body entity.Review
@@ -136,6 +191,24 @@ func (h *Handler) UpdateReview(ctx *gin.Context) {
 		return
 	}
 
+	if !h.authorizeReview(ctx, body.ID) {
+		return
+	}
+	body.EditedBy = ctx.GetHeader("sub")
+
+	// a new rating alone keeps the review as it is, new feedback is screened again
+	if body.Feedback != "" {
+		current, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: body.ID})
+		if h.HandleDbError(ctx, err, "Error getting review") {
+			return
+		}
+		body.UserID = current.UserID
+
+		if !h.screenReview(ctx, &body, entity.Filter{Column: "id", Type: "neq", Value: body.ID}) {
+			return
+		}
+	}
+
 	review, err := h.UseCase.ReviewRepo.Update(ctx, body)
 	if h.HandleDbError(ctx, err, "Error updating review") {
 		return
@@ -144,6 +217,61 @@ func (h *Handler) UpdateReview(ctx *gin.Context) {
 	ctx.JSON(200, review)
 }
 
+// GetReviewHistory godoc
+// @Router /review/{id}/history [get]
+// @Summary Get the history of a review
+// @Description Lists every version of a review newest first, with what changed compared to the version before. Only the author and admins can see it.
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.ReviewRevisionList
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) GetReviewHistory(ctx *gin.Context) {
+	var (
+		req    entity.Id
+		filter entity.GetListFilter
+	)
+
+	req.ID = ctx.Param("id")
+	if _, err := uuid.Parse(req.ID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid review id", http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.UseCase.ReviewRepo.GetSingle(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting review") {
+		return
+	}
+
+	if !isAdmin(ctx) && review.UserID != ctx.GetHeader("sub") {
+		h.forbidden(ctx, "Only the author and admins can see the history of a review")
+		return
+	}
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	filter.Page, _ = strconv.Atoi(page)
+	filter.Limit, _ = strconv.Atoi(limit)
+	filter.OrderBy = append(filter.OrderBy, entity.OrderBy{
+		Column: "revision",
+		Order:  "desc",
+	})
+
+	revisions, err := h.UseCase.ReviewRepo.GetRevisions(ctx, req, filter)
+	if h.HandleDbError(ctx, err, "Error getting review history") {
+		return
+	}
+
+	ctx.JSON(200, revisions)
+}
+
 // DeleteReview godoc
 // @Router /review/{id} [delete]
 // @Summary Delete a review
@@ -155,6 +283,7 @@ func (h *Handler) UpdateReview(ctx *gin.Context) {
 // @Param id path string true "Review ID"
 // @Success 200 {object} entity.SuccessResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 func (h *Handler) DeleteReview(ctx *gin.Context) {
 	var (
 		req entity.Id
@@ -162,11 +291,22 @@ func (h *Handler) DeleteReview(ctx *gin.Context) {
 
 	req.ID = ctx.Param("id")
 
+	if !h.authorizeReview(ctx, req.ID) {
+		return
+	}
+
+	photoKeys, ok := h.photoObjectKeys(ctx, "review", req.ID)
+	if !ok {
+		return
+	}
+
 	err := h.UseCase.ReviewRepo.Delete(ctx, req)
 	if h.HandleDbError(ctx, err, "Error deleting review") {
 		return
 	}
 
+	h.deletePhotoObjects(photoKeys...)
+
 	ctx.JSON(200, entity.SuccessResponse{
 		Message: "Review deleted successfully",
 	})
@@ -175,7 +315,7 @@ func (h *Handler) DeleteReview(ctx *gin.Context) {
 // SetReviewImage godoc
 // @Router /review/{id}/image [post]
 // @Summary Set an image for a review
-// @Description Upload an image for a specific review
+// @Description Uploads an image to the review's gallery and makes it the cover
 // @Tags review
 // @Accept multipart/form-data
 // @Produce json
@@ -184,6 +324,7 @@ func (h *Handler) DeleteReview(ctx *gin.Context) {
 // @Param file formData file true "Image file to upload"
 // @Success 200 {object} entity.Review
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 func (h *Handler) SetReviewImage(ctx *gin.Context) {
 	// Review ID validation
@@ -193,35 +334,16 @@ func (h *Handler) SetReviewImage(ctx *gin.Context) {
 		return
 	}
 
-	// File retrieval
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		h.ReturnError(ctx, config.ErrorBadRequest, "Error getting file", 400)
-		return
-	}
-
-	// Save the file temporarily
-	tempPath := "/tmp/" + file.Filename
-	if err := ctx.SaveUploadedFile(file, tempPath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+	if !h.authorizeReview(ctx, reviewID) {
 		return
 	}
-	// Generate a unique filename
-	filename := uuid.New().String() + "-" + file.Filename
 
-	// Upload to MinIO
-	minioURL, err := h.MinIO.Upload(filename, tempPath)
-	if h.HandleDbError(ctx, err, "Error uploading review image") {
+	if _, ok := h.uploadPhoto(ctx, "review", reviewID, true); !ok {
 		return
 	}
 
-	updateReq := entity.Review{
-		ID:     reviewID,
-		Photos: minioURL,
-	}
-
-	updatedReview, err := h.UseCase.ReviewRepo.Update(ctx, updateReq)
-	if h.HandleDbError(ctx, err, "Error updating review image") {
+	updatedReview, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: reviewID})
+	if h.HandleDbError(ctx, err, "Error getting review") {
 		return
 	}
 
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Reviews are pre-screened when they are posted and when their feedback
+// changes. Clean reviews are published right away, flagged ones wait in the
+// moderation queue until an admin approves or rejects them.
+
+// GetReviewQueue godoc
+// @Router /admin/reviews/queue [get]
+// @Summary Get the review moderation queue
+// @Description Lists the pending reviews oldest first, with the flags the pre-screen gave them
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.ReviewList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetReviewQueue(ctx *gin.Context) {
+	var (
+		req entity.GetListFilter
+	)
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "status",
+		Type:   "eq",
+		Value:  "pending",
+	})
+	req.OrderBy = append(req.OrderBy,
+		entity.OrderBy{Column: "updated_at", Order: "asc"},
+		entity.OrderBy{Column: "id", Order: "asc"},
+	)
+
+	reviews, err := h.UseCase.ReviewRepo.GetList(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting review queue") {
+		return
+	}
+
+	ctx.JSON(200, reviews)
+}
+
+// ApproveReview godoc
+// @Router /admin/reviews/{id}/approve [post]
+// @Summary Approve a review
+// @Description Publishes a pending or rejected review and notifies its author
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param body body entity.ReviewModerationRequest false "Optional note"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) ApproveReview(ctx *gin.Context) {
+	h.moderateReview(ctx, "published")
+}
+
+// RejectReview godoc
+// @Router /admin/reviews/{id}/reject [post]
+// @Summary Reject a review
+// @Description Takes a review off the business and notifies its author with the note as the reason
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param body body entity.ReviewModerationRequest true "Reason for the author"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RejectReview(ctx *gin.Context) {
+	h.moderateReview(ctx, "rejected")
+}
+
+func (h *Handler) moderateReview(ctx *gin.Context, status string) {
+	var (
+		body entity.ReviewModerationRequest
+	)
+
+	// the note of an approval is optional, so is the body
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	body.Note = strings.TrimSpace(body.Note)
+
+	if status == "rejected" && body.Note == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "note must give the author the reason of the rejection", http.StatusBadRequest)
+		return
+	}
+
+	reviewID := ctx.Param("id")
+	if _, err := uuid.Parse(reviewID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid review id", http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: reviewID})
+	if h.HandleDbError(ctx, err, "Error getting review") {
+		return
+	}
+
+	if review.Status == status {
+		h.ReturnError(ctx, config.ErrorBadRequest, "The review is already "+status, http.StatusBadRequest)
+		return
+	}
+
+	review, err = h.UseCase.ReviewRepo.Moderate(ctx, entity.ReviewModeration{
+		ReviewID:    reviewID,
+		Status:      status,
+		ModeratedBy: ctx.GetHeader("sub"),
+		Note:        body.Note,
+	})
+	if h.HandleDbError(ctx, err, "Error moderating review") {
+		return
+	}
+
+	h.notifyModeration(ctx, review)
+
+	ctx.JSON(200, review)
+}
+
+// notifyModeration tells the author of a review what an admin decided.
+func (h *Handler) notifyModeration(ctx *gin.Context, review entity.Review) {
+	business, err := h.UseCase.BusinessRepo.GetSingle(ctx, entity.Id{ID: review.BusinessID})
+	if err != nil {
+		h.Logger.Error(err, "Error getting business")
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: review.UserID})
+	if err != nil {
+		h.Logger.Error(err, "Error getting reviewer")
+		return
+	}
+
+	message := "Your review of " + business.Name + " has been published."
+	if review.Status == "rejected" {
+		message = "Your review of " + business.Name + " has been rejected. Reason: " + review.ModerationNote
+	} else if review.ModerationNote != "" {
+		message += " Note: " + review.ModerationNote
+	}
+
+	h.notifyUser(ctx, user, message)
+}
+
+// screenReview runs the pre-screen on the feedback of a review and sets its
+// status and flags. The feedback is compared with the author's newest other
+// reviews, except is the filter that leaves out the review itself.
+func (h *Handler) screenReview(ctx *gin.Context, review *entity.Review, except entity.Filter) bool {
+	others, err := h.UseCase.ReviewRepo.GetList(ctx, entity.GetListFilter{
+		Page:  1,
+		Limit: config.ReviewDuplicateLookback,
+		Filters: []entity.Filter{
+			{Column: "user_id", Type: "eq", Value: review.UserID},
+			except,
+		},
+		OrderBy: []entity.OrderBy{{Column: "created_at", Order: "desc"}},
+	})
+	if h.HandleDbError(ctx, err, "Error getting reviews of the author") {
+		return false
+	}
+
+	texts := make([]string, 0, len(others.Items))
+	for _, other := range others.Items {
+		texts = append(texts, other.Feedback)
+	}
+
+	review.Flags = h.Screener.Screen(review.Feedback, texts)
+	review.Status = "published"
+	if len(review.Flags) > 0 {
+		review.Status = "pending"
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var reviewReactionTypes = map[string]bool{
+	"useful": true,
+	"funny":  true,
+	"cool":   true,
+}
+
+// AddReviewReaction godoc
+// @Router /review/{id}/reactions/{type} [put]
+// @Summary React to a review
+// @Description Marks a review useful, funny or cool. Each type counts once per user, reacting again changes nothing. Users can't react to their own reviews.
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param type path string true "useful, funny or cool"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) AddReviewReaction(ctx *gin.Context) {
+	reaction, ok := h.bindReviewReaction(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.UseCase.ReviewReactionRepo.Create(ctx, reaction)
+	if h.HandleDbError(ctx, err, "Error adding reaction") {
+		return
+	}
+
+	h.returnReactedReview(ctx, reaction.ReviewID)
+}
+
+// DeleteReviewReaction godoc
+// @Router /review/{id}/reactions/{type} [delete]
+// @Summary Take back a reaction to a review
+// @Description Removes the caller's reaction of the given type, it is no error when there is none
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param type path string true "useful, funny or cool"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) DeleteReviewReaction(ctx *gin.Context) {
+	reaction, ok := h.bindReviewReaction(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.UseCase.ReviewReactionRepo.Delete(ctx, reaction)
+	if h.HandleDbError(ctx, err, "Error deleting reaction") {
+		return
+	}
+
+	h.returnReactedReview(ctx, reaction.ReviewID)
+}
+
+// bindReviewReaction reads the reaction of the caller from the path and
+// checks that the review exists and isn't the caller's own.
+func (h *Handler) bindReviewReaction(ctx *gin.Context) (entity.ReviewReaction, bool) {
+	reaction := entity.ReviewReaction{
+		ReviewID: ctx.Param("id"),
+		UserID:   ctx.GetHeader("sub"),
+		Type:     ctx.Param("type"),
+	}
+
+	if _, err := uuid.Parse(reaction.ReviewID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid review id", http.StatusBadRequest)
+		return entity.ReviewReaction{}, false
+	}
+
+	if !reviewReactionTypes[reaction.Type] {
+		h.ReturnError(ctx, config.ErrorBadRequest, "type must be useful, funny or cool", http.StatusBadRequest)
+		return entity.ReviewReaction{}, false
+	}
+
+	review, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: reaction.ReviewID})
+	if h.HandleDbError(ctx, err, "Error getting review") {
+		return entity.ReviewReaction{}, false
+	}
+
+	if review.Status != "published" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Only published reviews can get reactions", http.StatusBadRequest)
+		return entity.ReviewReaction{}, false
+	}
+
+	if review.UserID == reaction.UserID {
+		return entity.ReviewReaction{}, h.forbidden(ctx, "You can't react to your own review")
+	}
+
+	return reaction, true
+}
+
+func (h *Handler) returnReactedReview(ctx *gin.Context, reviewID string) {
+	review, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: reviewID})
+	if h.HandleDbError(ctx, err, "Error getting review") {
+		return
+	}
+
+	reviews := []entity.Review{review}
+	if !h.setMyReactions(ctx, reviews) {
+		return
+	}
+
+	ctx.JSON(200, reviews[0])
+}
+
+// setMyReactions fills MyReactions of the reviews for the caller.
+func (h *Handler) setMyReactions(ctx *gin.Context, reviews []entity.Review) bool {
+	types := map[string][]string{}
+
+	if userID := ctx.GetHeader("sub"); userID != "" {
+		ids := make([]string, 0, len(reviews))
+		for _, review := range reviews {
+			ids = append(ids, review.ID)
+		}
+
+		var err error
+		types, err = h.UseCase.ReviewReactionRepo.GetTypes(ctx, userID, ids...)
+		if h.HandleDbError(ctx, err, "Error getting reactions") {
+			return false
+		}
+	}
+
+	for i := range reviews {
+		reviews[i].MyReactions = types[reviews[i].ID]
+		if reviews[i].MyReactions == nil {
+			reviews[i].MyReactions = []string{}
+		}
+	}
+
+	return true
+}
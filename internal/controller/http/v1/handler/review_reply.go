@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetReviewReply godoc
+// @Router /review/{id}/reply [put]
+// @Summary Reply to a review
+// @Description Writes or replaces the public reply of the reviewed business, any member of the business can. The reviewer is notified of a new reply.
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Param body body entity.ReviewReplyRequest true "Reply"
+// @Success 200 {object} entity.ReviewReply
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) SetReviewReply(ctx *gin.Context) {
+	var (
+		body entity.ReviewReplyRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	body.Message = strings.TrimSpace(body.Message)
+	if body.Message == "" || utf8.RuneCountInString(body.Message) > config.ReviewReplyMaxLength {
+		h.ReturnError(ctx, config.ErrorBadRequest, "message must have 1 to "+strconv.Itoa(config.ReviewReplyMaxLength)+" characters", http.StatusBadRequest)
+		return
+	}
+
+	review, ok := h.authorizeReviewReply(ctx)
+	if !ok {
+		return
+	}
+
+	if review.Status != "published" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Only published reviews can be replied to", http.StatusBadRequest)
+		return
+	}
+
+	reply, created, err := h.UseCase.ReviewReplyRepo.Set(ctx, entity.ReviewReply{
+		ReviewID: review.ID,
+		AuthorID: ctx.GetHeader("sub"),
+		Message:  body.Message,
+	})
+	if h.HandleDbError(ctx, err, "Error setting review reply") {
+		return
+	}
+
+	// only a new reply is news to the reviewer, not an edit
+	if created {
+		h.notifyReviewer(ctx, review)
+	}
+
+	ctx.JSON(200, reply)
+}
+
+// DeleteReviewReply godoc
+// @Router /review/{id}/reply [delete]
+// @Summary Delete the reply to a review
+// @Description Any member of the reviewed business can delete its reply
+// @Security BearerAuth
+// @Tags review
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Review ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) DeleteReviewReply(ctx *gin.Context) {
+	review, ok := h.authorizeReviewReply(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.UseCase.ReviewReplyRepo.Delete(ctx, entity.Id{ID: review.ID})
+	if h.HandleDbError(ctx, err, "Error deleting review reply") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Reply deleted successfully",
+	})
+}
+
+// authorizeReviewReply returns the review in the path when the caller is a
+// member of the reviewed business.
+func (h *Handler) authorizeReviewReply(ctx *gin.Context) (entity.Review, bool) {
+	reviewID := ctx.Param("id")
+	if _, err := uuid.Parse(reviewID); err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid review id", http.StatusBadRequest)
+		return entity.Review{}, false
+	}
+
+	review, err := h.UseCase.ReviewRepo.GetSingle(ctx, entity.Id{ID: reviewID})
+	if h.HandleDbError(ctx, err, "Error getting review") {
+		return entity.Review{}, false
+	}
+
+	if !h.authorizeBusiness(ctx, review.BusinessID, "editor") {
+		return entity.Review{}, false
+	}
+
+	return review, true
+}
+
+// notifyReviewer tells the author of a review that the business replied to it.
+func (h *Handler) notifyReviewer(ctx *gin.Context, review entity.Review) {
+	business, err := h.UseCase.BusinessRepo.GetSingle(ctx, entity.Id{ID: review.BusinessID})
+	if err != nil {
+		h.Logger.Error(err, "Error getting business")
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: review.UserID})
+	if err != nil {
+		h.Logger.Error(err, "Error getting reviewer")
+		return
+	}
+
+	h.notifyUser(ctx, user, business.Name+" replied to your review.")
+}
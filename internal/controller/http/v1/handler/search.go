@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchSuggest godoc
+// @Router /search/suggest [get]
+// @Summary Suggest names while typing
+// @Description Returns business, category and event names that start with or resemble q, best match first. Open a suggestion by its type and id. No login is needed.
+// @Tags search
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Typed text"
+// @Param limit query number false "Number of suggestions, 10 by default"
+// @Param lang query string false "Language of category names, en by default"
+// @Success 200 {object} entity.SuggestionList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) SearchSuggest(ctx *gin.Context) {
+	var (
+		req entity.SuggestRequest
+	)
+
+	req.Query = strings.TrimSpace(ctx.Query("q"))
+	if req.Query == "" || utf8.RuneCountInString(req.Query) > config.BusinessSearchMaxLength {
+		h.ReturnError(ctx, config.ErrorBadRequest, "q must have 1 to "+strconv.Itoa(config.BusinessSearchMaxLength)+" characters", http.StatusBadRequest)
+		return
+	}
+
+	req.Limit, _ = strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if req.Limit <= 0 || req.Limit > config.SearchSuggestMaxLimit {
+		h.ReturnError(ctx, config.ErrorBadRequest, "limit must be 1 to "+strconv.Itoa(config.SearchSuggestMaxLimit), http.StatusBadRequest)
+		return
+	}
+
+	req.Lang = ctx.DefaultQuery("lang", "en")
+	if !languageCodeRegexp.MatchString(req.Lang) {
+		h.ReturnError(ctx, config.ErrorBadRequest, "lang must be a two letter language code", http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.UseCase.SearchRepo.Suggest(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting suggestions") {
+		return
+	}
+
+	ctx.JSON(200, suggestions)
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/useragent"
 )
 
 // GetSession godoc
@@ -151,3 +154,119 @@ func (h *Handler) DeleteSession(ctx *gin.Context) {
 		Message: "Session deleted successfully",
 	})
 }
+
+// GetDevices godoc
+// @Router /session/devices [get]
+// @Summary List my devices
+// @Description Lists the caller's active sessions with the browser and operating system they were opened from, most recently used first
+// @Security BearerAuth
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Param page query number false "page"
+// @Param limit query number false "limit"
+// @Success 200 {object} entity.DeviceList
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetDevices(ctx *gin.Context) {
+	var (
+		response entity.DeviceList
+	)
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	// sessions that were never used yet count as used when they were opened
+	sessions, err := h.UseCase.SessionRepo.GetList(ctx, entity.GetListFilter{
+		Page:  page,
+		Limit: limit,
+		Filters: []entity.Filter{
+			{Column: "user_id", Type: "eq", Value: ctx.GetHeader("sub")},
+			{Column: "is_active", Type: "eq", Value: "true"},
+			{Column: "expires_at", Type: "gt", Value: time.Now().Format(time.RFC3339)},
+		},
+		OrderBy: []entity.OrderBy{
+			{Column: "COALESCE(last_active_at, created_at)", Order: "desc"},
+			{Column: "id", Order: "desc"},
+		},
+	})
+	if h.HandleDbError(ctx, err, "Error getting sessions") {
+		return
+	}
+
+	response.Items = []entity.Device{}
+	currentID := ctx.GetHeader("session_id")
+	for _, session := range sessions.Items {
+		ua := useragent.Parse(session.UserAgent)
+		response.Items = append(response.Items, entity.Device{
+			SessionID:      session.ID,
+			Browser:        ua.Browser,
+			BrowserVersion: ua.BrowserVersion,
+			OS:             ua.OS,
+			OSVersion:      ua.OSVersion,
+			Platform:       session.Platform,
+			IPAddress:      session.IPAddress,
+			LastActiveAt:   session.LastActiveAt,
+			CreatedAt:      session.CreatedAt,
+			IsCurrent:      session.ID == currentID,
+		})
+	}
+	response.Count = sessions.Count
+
+	ctx.JSON(200, response)
+}
+
+// RevokeDevice godoc
+// @Router /session/devices/{id} [delete]
+// @Summary Log out a device
+// @Description Revokes one of the caller's sessions
+// @Security BearerAuth
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+func (h *Handler) RevokeDevice(ctx *gin.Context) {
+	session, err := h.UseCase.SessionRepo.GetSingle(ctx, entity.Id{ID: ctx.Param("id")})
+	if h.HandleDbError(ctx, err, "Error getting session") {
+		return
+	}
+
+	if session.UserID != ctx.GetHeader("sub") {
+		h.ReturnError(ctx, config.ErrorNotFound, "The requested resource was not found.", http.StatusNotFound)
+		return
+	}
+
+	h.revokeSession(ctx, session.ID)
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Device logged out successfully",
+	})
+}
+
+// RevokeOtherDevices godoc
+// @Router /session/devices [delete]
+// @Summary Log out all other devices
+// @Description Revokes every session of the caller except the one making the request
+// @Security BearerAuth
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} entity.RowsEffected
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) RevokeOtherDevices(ctx *gin.Context) {
+	rows, err := h.UseCase.SessionRepo.UpdateField(ctx, entity.UpdateFieldRequest{
+		Filter: []entity.Filter{
+			{Column: "user_id", Type: "eq", Value: ctx.GetHeader("sub")},
+			{Column: "id", Type: "neq", Value: ctx.GetHeader("session_id")},
+			{Column: "is_active", Type: "eq", Value: "true"},
+		},
+		Items: []entity.UpdateFieldItem{{Column: "is_active", Value: "false"}},
+	})
+	if h.HandleDbError(ctx, err, "Error revoking sessions") {
+		return
+	}
+
+	ctx.JSON(200, rows)
+}
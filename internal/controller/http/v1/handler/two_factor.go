@@ -0,0 +1,421 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/etc"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/hash"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/totp"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+const recoveryCodeCount = 10
+
+// SetupTwoFactor godoc
+// @Router /auth/2fa/setup [post]
+// @Summary Start two-factor setup
+// @Description Generates a new TOTP secret for the caller. Either authenticate with a bearer token or pass the challenge token returned by login when setup is required.
+// @Security BearerAuth
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.TwoFactorSetupRequest false "Challenge token"
+// @Success 200 {object} entity.TwoFactorSetupResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+func (h *Handler) SetupTwoFactor(ctx *gin.Context) {
+	var (
+		body entity.TwoFactorSetupRequest
+	)
+
+	_ = ctx.ShouldBindJSON(&body)
+
+	user, _, ok := h.twoFactorUser(ctx, body.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	current, err := h.UseCase.TotpRepo.GetSingle(ctx, entity.Id{ID: user.ID})
+	if err == nil && current.IsEnabled {
+		h.ReturnError(ctx, config.ErrorConflict, "Two-factor authentication is already enabled", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.UseCase.TotpRepo.Create(ctx, entity.UserTotp{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if h.HandleDbError(ctx, err, "Error creating totp") {
+		return
+	}
+
+	ctx.JSON(200, entity.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(h.Config.TotpIssuer, user.Email, secret),
+	})
+}
+
+// EnableTwoFactor godoc
+// @Router /auth/2fa/enable [post]
+// @Summary Enable two-factor authentication
+// @Description Confirms setup with a code from the authenticator app and returns one-time recovery codes. They are shown only once.
+// @Security BearerAuth
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.TwoFactorEnableRequest true "Code"
+// @Success 200 {object} entity.TwoFactorEnableResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+func (h *Handler) EnableTwoFactor(ctx *gin.Context) {
+	var (
+		body entity.TwoFactorEnableRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	user, challenge, ok := h.twoFactorUser(ctx, body.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	current, err := h.UseCase.TotpRepo.GetSingle(ctx, entity.Id{ID: user.ID})
+	if err == pgx.ErrNoRows {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Two-factor setup was not started", http.StatusBadRequest)
+		return
+	}
+	if h.HandleDbError(ctx, err, "Error getting totp") {
+		return
+	}
+
+	if current.IsEnabled {
+		h.ReturnError(ctx, config.ErrorConflict, "Two-factor authentication is already enabled", http.StatusBadRequest)
+		return
+	}
+
+	valid, err := h.checkSecondFactor(ctx, &current, body.Code, "")
+	if h.HandleDbError(ctx, err, "Error checking totp") {
+		return
+	}
+
+	if !valid {
+		h.ReturnError(ctx, config.ErrorInvalidOtp, "Incorrect code", http.StatusBadRequest)
+		return
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	current.RecoveryCodes = make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := etc.GenerateToken(5)
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+			return
+		}
+		codes = append(codes, code)
+		current.RecoveryCodes = append(current.RecoveryCodes, hash.HashToken(code))
+	}
+
+	current.IsEnabled = true
+	_, err = h.UseCase.TotpRepo.Update(ctx, current)
+	if h.HandleDbError(ctx, err, "Error enabling totp") {
+		return
+	}
+
+	// a login that was waiting for setup can now be completed with /auth/2fa/verify
+	if challenge != nil {
+		challenge.SetupRequired = false
+		err = h.saveTwoFactorChallenge(ctx, body.ChallengeToken, *challenge)
+		if err != nil {
+			h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx.JSON(200, entity.TwoFactorEnableResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor godoc
+// @Router /auth/2fa/disable [post]
+// @Summary Disable two-factor authentication
+// @Description Disables two-factor authentication after checking a code or a recovery code
+// @Security BearerAuth
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.TwoFactorDisableRequest true "Code"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) DisableTwoFactor(ctx *gin.Context) {
+	var (
+		body entity.TwoFactorDisableRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	userID := ctx.GetHeader("sub")
+	if userID == "" {
+		h.ReturnError(ctx, config.ErrorUnauthorized, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if h.twoFactorRequired(ctx.GetHeader("user_type")) {
+		h.ReturnError(ctx, config.ErrorForbidden, "Two-factor authentication is required for your account", http.StatusForbidden)
+		return
+	}
+
+	current, err := h.UseCase.TotpRepo.GetSingle(ctx, entity.Id{ID: userID})
+	if h.HandleDbError(ctx, err, "Error getting totp") {
+		return
+	}
+
+	valid, err := h.checkSecondFactor(ctx, &current, body.Code, body.RecoveryCode)
+	if h.HandleDbError(ctx, err, "Error checking totp") {
+		return
+	}
+
+	if !valid {
+		h.ReturnError(ctx, config.ErrorInvalidOtp, "Incorrect code", http.StatusBadRequest)
+		return
+	}
+
+	err = h.UseCase.TotpRepo.Delete(ctx, entity.Id{ID: userID})
+	if h.HandleDbError(ctx, err, "Error disabling totp") {
+		return
+	}
+
+	ctx.JSON(200, entity.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// VerifyTwoFactor godoc
+// @Router /auth/2fa/verify [post]
+// @Summary Complete two-factor login
+// @Description Second step of login. Exchanges the challenge token and a TOTP or recovery code for a session.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body entity.TwoFactorVerifyRequest true "Challenge"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+func (h *Handler) VerifyTwoFactor(ctx *gin.Context) {
+	var (
+		body entity.TwoFactorVerifyRequest
+	)
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
+		return
+	}
+
+	key := "2fa-challenge-" + body.ChallengeToken
+
+	challenge, err := h.getTwoFactorChallenge(ctx, body.ChallengeToken)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInvalidToken, "Challenge is invalid or expired, please log in again", http.StatusUnauthorized)
+		return
+	}
+
+	if challenge.SetupRequired {
+		h.ReturnError(ctx, config.ErrorForbidden, "Two-factor authentication must be set up first", http.StatusForbidden)
+		return
+	}
+
+	// wrong codes are counted per user rather than per challenge, so logging
+	// in again with the password doesn't give a fresh set of guesses
+	failuresKey := "2fa-failures-" + challenge.UserID
+	failures, err := h.UseCase.AttemptRepo.Get(ctx, failuresKey)
+	if h.HandleDbError(ctx, err, "Error getting failed attempts") {
+		return
+	}
+	if failures >= config.OtpMaxAttempts {
+		_ = h.Redis.Del(ctx, key)
+		h.ReturnError(ctx, config.ErrorTooManyRequest, "Too many incorrect attempts, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	current, err := h.UseCase.TotpRepo.GetSingle(ctx, entity.Id{ID: challenge.UserID})
+	if h.HandleDbError(ctx, err, "Error getting totp") {
+		return
+	}
+
+	valid, err := h.checkSecondFactor(ctx, &current, body.Code, body.RecoveryCode)
+	if h.HandleDbError(ctx, err, "Error checking totp") {
+		return
+	}
+
+	if !valid {
+		h.recordLoginFailure(ctx, entity.User{ID: challenge.UserID}, "", challenge.Platform, "invalid_second_factor")
+
+		failures, err := h.failedAttempt(ctx, failuresKey, config.LoginFailureWindow)
+		if err != nil || failures >= config.OtpMaxAttempts {
+			_ = h.Redis.Del(ctx, key)
+			h.ReturnError(ctx, config.ErrorTooManyRequest, "Too many incorrect attempts, please try again later", http.StatusTooManyRequests)
+			return
+		}
+		h.ReturnError(ctx, config.ErrorInvalidOtp, "Incorrect code", http.StatusBadRequest)
+		return
+	}
+
+	_ = h.UseCase.AttemptRepo.Reset(ctx, failuresKey)
+
+	err = h.Redis.Del(ctx, key)
+	if err != nil {
+		h.ReturnError(ctx, config.ErrorInternalServer, "Oops, something went wrong!!!", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: challenge.UserID})
+	if h.HandleDbError(ctx, err, "Error getting user") {
+		return
+	}
+
+	session, err := h.newSession(ctx, &user, challenge.Platform)
+	if h.HandleDbError(ctx, err, "Error while creating new session") {
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"user":    user,
+		"session": session,
+	})
+}
+
+// twoFactorRequired reports whether the policy forces two-factor login for the user type.
+func (h *Handler) twoFactorRequired(userType string) bool {
+	for _, t := range h.Config.TotpRequiredFor {
+		if strings.TrimSpace(t) == userType {
+			return true
+		}
+	}
+	return false
+}
+
+// twoFactorUser resolves who is enrolling: the caller of an authenticated request,
+// or the owner of a login challenge that is waiting for setup.
+func (h *Handler) twoFactorUser(ctx *gin.Context, challengeToken string) (entity.User, *entity.TwoFactorChallengeState, bool) {
+	var challenge *entity.TwoFactorChallengeState
+
+	userID := ctx.GetHeader("sub")
+	if userID == "" {
+		state, err := h.getTwoFactorChallenge(ctx, challengeToken)
+		if err != nil || !state.SetupRequired {
+			h.ReturnError(ctx, config.ErrorUnauthorized, "Unauthorized", http.StatusUnauthorized)
+			return entity.User{}, nil, false
+		}
+		userID = state.UserID
+		challenge = &state
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: userID})
+	if h.HandleDbError(ctx, err, "Error getting user") {
+		return entity.User{}, nil, false
+	}
+
+	if user.UserType != "admin" && user.UserType != "businessman" {
+		h.ReturnError(ctx, config.ErrorForbidden, "Two-factor authentication is available for admin and businessman accounts", http.StatusForbidden)
+		return entity.User{}, nil, false
+	}
+
+	return user, challenge, true
+}
+
+// checkSecondFactor accepts either a TOTP code that hasn't been used yet or an unused recovery code.
+func (h *Handler) checkSecondFactor(ctx *gin.Context, t *entity.UserTotp, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := totp.Validate(t.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		rows, err := h.UseCase.TotpRepo.UseStep(ctx, entity.UserTotp{UserID: t.UserID, LastUsedStep: step})
+		if err != nil {
+			return false, err
+		}
+		return rows.RowsEffected > 0, nil
+	}
+
+	if recoveryCode != "" && t.IsEnabled {
+		// removed in a single statement, so two requests can't both use the code
+		rows, err := h.UseCase.TotpRepo.UseRecoveryCode(ctx, entity.UserTotp{
+			UserID:        t.UserID,
+			RecoveryCodes: []string{hash.HashToken(strings.ToLower(strings.TrimSpace(recoveryCode)))},
+		})
+		if err != nil {
+			return false, err
+		}
+		return rows.RowsEffected > 0, nil
+	}
+
+	return false, nil
+}
+
+// newTwoFactorChallenge stores a pending login that still needs the second factor.
+func (h *Handler) newTwoFactorChallenge(ctx *gin.Context, state entity.TwoFactorChallengeState) (entity.TwoFactorChallenge, error) {
+	token, err := etc.GenerateToken(32)
+	if err != nil {
+		return entity.TwoFactorChallenge{}, err
+	}
+
+	err = h.saveTwoFactorChallenge(ctx, token, state)
+	if err != nil {
+		return entity.TwoFactorChallenge{}, err
+	}
+
+	return entity.TwoFactorChallenge{
+		TwoFactorRequired: true,
+		SetupRequired:     state.SetupRequired,
+		ChallengeToken:    token,
+		ExpiresIn:         int(config.OtpExpireTime.Seconds()),
+	}, nil
+}
+
+func (h *Handler) saveTwoFactorChallenge(ctx *gin.Context, token string, state entity.TwoFactorChallengeState) error {
+	js, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return h.Redis.Set(ctx, "2fa-challenge-"+token, string(js), int(config.OtpExpireTime.Seconds()))
+}
+
+func (h *Handler) getTwoFactorChallenge(ctx *gin.Context, token string) (entity.TwoFactorChallengeState, error) {
+	var state entity.TwoFactorChallengeState
+
+	if token == "" {
+		return state, errOtpExpired
+	}
+
+	js, err := h.Redis.Get(ctx, "2fa-challenge-"+token)
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal([]byte(js), &state)
+	return state, err
+}
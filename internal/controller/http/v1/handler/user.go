@@ -192,11 +192,18 @@ func (h *Handler) DeleteUser(ctx *gin.Context) {
 		req.ID = ctx.GetHeader("sub")
 	}
 
+	photoKeys, ok := h.photoObjectKeys(ctx, "user", req.ID)
+	if !ok {
+		return
+	}
+
 	err := h.UseCase.UserRepo.Delete(ctx, req)
 	if h.HandleDbError(ctx, err, "Error deleting user") {
 		return
 	}
 
+	h.deletePhotoObjects(photoKeys...)
+
 	ctx.JSON(200, entity.SuccessResponse{
 		Message: "User deleted successfully",
 	})
@@ -240,7 +247,7 @@ func (h *Handler) UploadImage(c *gin.Context) {
 // SetUserAvatar godoc
 // @Router /user/avatar [post]
 // @Summary Set user avatar
-// @Description Upload an avatar to MinIO and update user's avatar ID in the database
+// @Description Uploads an avatar and makes it the current one, earlier avatars stay in /user/{id}/photos
 // @Security BearerAuth
 // @Tags user
 // @Accept multipart/form-data
@@ -256,33 +263,141 @@ func (h *Handler) SetUserAvatar(ctx *gin.Context) {
 		return
 	}
 
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, err)
+	if _, ok := h.uploadPhoto(ctx, "user", userID, true); !ok {
 		return
 	}
-	filename := uuid.New().String() + "-" + file.Filename
 
-	tempPath := "/tmp/" + file.Filename 
-	if err := ctx.SaveUploadedFile(file, tempPath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+	updatedUser, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: userID})
+	if h.HandleDbError(ctx, err, "Error getting user") {
 		return
 	}
+	updatedUser.Password = " "
 
-	minioURL, err := h.MinIO.Upload(filename, tempPath)
+	ctx.JSON(200, updatedUser)
+}
+
+// UpdateUserStatus godoc
+// @Router /admin/users/{id}/status [put]
+// @Summary Block or unblock a user
+// @Description Changes the user's status. Blocking logs the user out of every session immediately. The user is notified about the change.
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param body body entity.UserStatusChange true "Status (active or blocked) and reason"
+// @Success 200 {object} entity.UserStatusChange
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+func (h *Handler) UpdateUserStatus(ctx *gin.Context) {
+	var (
+		body entity.UserStatusChange
+	)
+
+	err := ctx.ShouldBindJSON(&body)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+		h.ReturnError(ctx, config.ErrorBadRequest, "Invalid request body", 400)
 		return
 	}
-	updateReq := entity.User{
-		ID:       userID,
-		AvatarId: minioURL,
+
+	if body.Status != "active" && body.Status != "blocked" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Status must be active or blocked", 400)
+		return
 	}
 
-	updatedUser, err := h.UseCase.UserRepo.Update(ctx, updateReq)
-	if h.HandleDbError(ctx, err, "Error updating user avatar") {
+	if body.Status == "blocked" && body.Reason == "" {
+		h.ReturnError(ctx, config.ErrorBadRequest, "Reason is required to block a user", 400)
 		return
 	}
 
-	ctx.JSON(200, updatedUser)
+	body.UserID = ctx.Param("id")
+	body.ChangedBy = ctx.GetHeader("sub")
+
+	if body.UserID == body.ChangedBy {
+		h.ReturnError(ctx, config.ErrorForbidden, "You can't change your own status", http.StatusForbidden)
+		return
+	}
+
+	user, err := h.UseCase.UserRepo.GetSingle(ctx, entity.UserSingleRequest{ID: body.UserID})
+	if h.HandleDbError(ctx, err, "Error getting user") {
+		return
+	}
+
+	if user.UserRole == "superadmin" && ctx.GetHeader("user_role") != "superadmin" {
+		h.ReturnError(ctx, config.ErrorForbidden, "Only a superadmin can change a superadmin's status", http.StatusForbidden)
+		return
+	}
+
+	change, err := h.UseCase.UserRepo.UpdateStatus(ctx, body)
+	if h.HandleDbError(ctx, err, "Error updating user status") {
+		return
+	}
+
+	message := "Your account has been unblocked."
+	if body.Status == "blocked" {
+		_, err = h.UseCase.SessionRepo.UpdateField(ctx, entity.UpdateFieldRequest{
+			Filter: []entity.Filter{{Column: "user_id", Type: "eq", Value: user.ID}},
+			Items:  []entity.UpdateFieldItem{{Column: "is_active", Value: "false"}},
+		})
+		if h.HandleDbError(ctx, err, "Error deactivating sessions") {
+			return
+		}
+
+		message = "Your account has been blocked. Reason: " + body.Reason
+	}
+
+	_, err = h.UseCase.NotificationRepo.Create(ctx, entity.Notification{
+		OwnerId: body.ChangedBy,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Message: message,
+		Status:  "unread",
+	})
+	if err != nil {
+		h.Logger.Error(err, "Error creating status notification")
+	}
+
+	ctx.JSON(200, change)
+}
+
+// GetUserStatusHistory godoc
+// @Router /admin/users/{id}/status-history [get]
+// @Summary Get a user's status history
+// @Description Lists the status changes of a user with reasons, newest first
+// @Security BearerAuth
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param page query number true "page"
+// @Param limit query number true "limit"
+// @Success 200 {object} entity.UserStatusHistory
+// @Failure 400 {object} entity.ErrorResponse
+func (h *Handler) GetUserStatusHistory(ctx *gin.Context) {
+	var (
+		req entity.GetListFilter
+	)
+
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	req.Page, _ = strconv.Atoi(page)
+	req.Limit, _ = strconv.Atoi(limit)
+	req.Filters = append(req.Filters, entity.Filter{
+		Column: "user_id",
+		Type:   "eq",
+		Value:  ctx.Param("id"),
+	})
+
+	req.OrderBy = append(req.OrderBy, entity.OrderBy{
+		Column: "created_at",
+		Order:  "desc",
+	})
+
+	history, err := h.UseCase.UserRepo.GetStatusHistory(ctx, req)
+	if h.HandleDbError(ctx, err, "Error getting user status history") {
+		return
+	}
+
+	ctx.JSON(200, history)
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/internal/controller/http/v1/handler"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/usecase"
 	minio "github.com/Avazbek-02/udevslab-lesson6/pkg/MinIO"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/geo"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/jwt"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	rediscache "github.com/golanguzb70/redis-cache"
 )
@@ -29,14 +31,13 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useCase *usecase.UseCase, redis rediscache.RedisCache, minio *minio.MinIO) {
+func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useCase *usecase.UseCase, redis rediscache.RedisCache, minio *minio.MinIO, jwtKeys *jwt.KeySet, enforcer *casbin.SyncedEnforcer, gazetteer *geo.Gazetteer) {
 	engine.Use(gin.Logger())
 	engine.Use(gin.Recovery())
 
-	handlerV1 := handler.NewHandler(l, config, useCase, redis,minio)
+	handlerV1 := handler.NewHandler(l, config, useCase, redis,minio, jwtKeys, enforcer, gazetteer)
 
-	e := casbin.NewEnforcer("config/rbac.conf", "config/policy.csv")
-	engine.Use(handlerV1.AuthMiddleware(e))
+	engine.Use(handlerV1.AuthMiddleware(enforcer))
 
 	url := ginSwagger.URL("swagger/doc.json")
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
@@ -45,6 +46,8 @@ func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useC
 
 	engine.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	engine.GET("/.well-known/jwks.json", handlerV1.GetJWKS)
+
 	v1 := engine.Group("/v1")
 
 	user := v1.Group("/user")
@@ -52,14 +55,42 @@ func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useC
 		user.POST("/", handlerV1.CreateUser)
 		user.GET("/list", handlerV1.GetUsers)
 		user.GET("/:id", handlerV1.GetUser)
+		user.GET("/:id/photos", handlerV1.GetUserPhotos)
 		user.PUT("/", handlerV1.UpdateUser)
 		user.POST("/avatar", handlerV1.SetUserAvatar)
+		user.GET("/login-events", handlerV1.GetLoginEvents)
 		user.DELETE("/:id", handlerV1.DeleteUser)
 	}
 
+	admin := v1.Group("/admin")
+	{
+		admin.PUT("/users/:id/status", handlerV1.UpdateUserStatus)
+		admin.GET("/users/:id/status-history", handlerV1.GetUserStatusHistory)
+		admin.GET("/users/:id/login-events", handlerV1.GetUserLoginEvents)
+		admin.POST("/categories", handlerV1.CreateCategory)
+		admin.PUT("/categories/:id", handlerV1.UpdateCategory)
+		admin.DELETE("/categories/:id", handlerV1.DeleteCategory)
+		admin.GET("/reviews/queue", handlerV1.GetReviewQueue)
+		admin.POST("/reviews/:id/approve", handlerV1.ApproveReview)
+		admin.POST("/reviews/:id/reject", handlerV1.RejectReview)
+	}
+
+	rbac := v1.Group("/rbac")
+	{
+		rbac.GET("/policies", handlerV1.GetPolicies)
+		rbac.POST("/policies", handlerV1.AddPolicy)
+		rbac.DELETE("/policies", handlerV1.RemovePolicy)
+		rbac.GET("/roles", handlerV1.GetRoleGrants)
+		rbac.POST("/roles", handlerV1.AddRoleGrant)
+		rbac.DELETE("/roles", handlerV1.RemoveRoleGrant)
+	}
+
 	session := v1.Group("/session")
 	{
 		session.GET("/list", handlerV1.GetSessions)
+		session.GET("/devices", handlerV1.GetDevices)
+		session.DELETE("/devices", handlerV1.RevokeOtherDevices)
+		session.DELETE("/devices/:id", handlerV1.RevokeDevice)
 		session.GET("/:id", handlerV1.GetSession)
 		session.PUT("/", handlerV1.UpdateSession)
 		session.DELETE("/:id", handlerV1.DeleteSession)
@@ -69,8 +100,18 @@ func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useC
 	{
 		auth.POST("/logout", handlerV1.Logout)
 		auth.POST("/register", handlerV1.Register)
+		auth.GET("/oidc/login", handlerV1.OidcLogin)
+		auth.GET("/oidc/callback", handlerV1.OidcCallback)
 		auth.POST("/verify-email", handlerV1.VerifyEmail)
+		auth.POST("/resend-otp", handlerV1.ResendOtp)
 		auth.POST("/login", handlerV1.Login)
+		auth.POST("/refresh", handlerV1.RefreshToken)
+		auth.POST("/forgot-password", handlerV1.ForgotPassword)
+		auth.POST("/reset-password", handlerV1.ResetPassword)
+		auth.POST("/2fa/setup", handlerV1.SetupTwoFactor)
+		auth.POST("/2fa/enable", handlerV1.EnableTwoFactor)
+		auth.POST("/2fa/disable", handlerV1.DisableTwoFactor)
+		auth.POST("/2fa/verify", handlerV1.VerifyTwoFactor)
 	}
 	business := v1.Group("/business")
 	{
@@ -80,6 +121,38 @@ func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useC
 		business.PUT("/", handlerV1.UpdateBusiness)
 		business.DELETE("/:id", handlerV1.DeleteBusiness)
 		business.POST("/:id/image", handlerV1.SetBusinessImage)
+		business.POST("/:id/photos", handlerV1.AddBusinessPhoto)
+		business.GET("/:id/photos", handlerV1.GetBusinessPhotos)
+		business.GET("/:id/members", handlerV1.GetBusinessMembers)
+		business.PUT("/:id/members/:user_id", handlerV1.UpdateBusinessMemberRole)
+		business.DELETE("/:id/members/:user_id", handlerV1.RemoveBusinessMember)
+		business.POST("/:id/transfer", handlerV1.TransferBusinessOwnership)
+		business.POST("/:id/invitations", handlerV1.InviteBusinessMember)
+		business.GET("/:id/invitations", handlerV1.GetBusinessInvitations)
+		business.DELETE("/:id/invitations/:invitation_id", handlerV1.RevokeBusinessInvitation)
+		business.POST("/invitations/accept", handlerV1.AcceptBusinessInvitation)
+		business.GET("/:id/hours", handlerV1.GetBusinessHours)
+		business.PUT("/:id/hours", handlerV1.SetBusinessHours)
+		business.PUT("/:id/hours/overrides/:date", handlerV1.SetBusinessHourOverride)
+		business.DELETE("/:id/hours/overrides/:date", handlerV1.DeleteBusinessHourOverride)
+	}
+
+	category := v1.Group("/category")
+	{
+		category.GET("/list", handlerV1.GetCategories)
+		category.GET("/:id", handlerV1.GetCategory)
+	}
+
+	photo := v1.Group("/photo")
+	{
+		photo.GET("/:id", handlerV1.GetPhoto)
+		photo.PUT("/:id", handlerV1.UpdatePhoto)
+		photo.DELETE("/:id", handlerV1.DeletePhoto)
+	}
+
+	search := v1.Group("/search")
+	{
+		search.GET("/suggest", handlerV1.SearchSuggest)
 	}
 
 	review := v1.Group("/review")
@@ -90,6 +163,13 @@ func NewRouter(engine *gin.Engine, l *logger.Logger, config *config.Config, useC
 		review.PUT("/", handlerV1.UpdateReview)
 		review.DELETE("/:id", handlerV1.DeleteReview)
 		review.POST("/:id/image", handlerV1.SetReviewImage)
+		review.POST("/:id/photos", handlerV1.AddReviewPhoto)
+		review.GET("/:id/photos", handlerV1.GetReviewPhotos)
+		review.GET("/:id/history", handlerV1.GetReviewHistory)
+		review.PUT("/:id/reply", handlerV1.SetReviewReply)
+		review.DELETE("/:id/reply", handlerV1.DeleteReviewReply)
+		review.PUT("/:id/reactions/:type", handlerV1.AddReviewReaction)
+		review.DELETE("/:id/reactions/:type", handlerV1.DeleteReviewReaction)
 	}
 
 	report := v1.Group("/report")
@@ -20,3 +20,55 @@ type VerifyEmail struct {
 	Otp      string `json:"otp"`
 	Platform string `json:"platform"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	SessionID    string `json:"session_id"`
+}
+
+type RefreshToken struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	TokenHash string `json:"-"`
+	IsUsed    bool   `json:"is_used"`
+	IsRevoked bool   `json:"is_revoked"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ResendOtpRequest struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	Otp         string `json:"otp"`
+	NewPassword string `json:"new_password"`
+}
+
+type LoginEvent struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failure_reason"`
+	IPAddress     string `json:"ip_address"`
+	UserAgent     string `json:"user_agent"`
+	Platform      string `json:"platform"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type LoginEventList struct {
+	Items []LoginEvent `json:"login_events"`
+	Count int          `json:"count"`
+}
@@ -5,15 +5,202 @@ type Business struct {
 	OwnerID     string `json:"owner_id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Category    string `json:"category"`
 	Address     string `json:"address"`
 	ContactInfo string `json:"contact_info"`
 	Photos      string `json:"photos"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	// PriceLevel is 1 (cheap) to 4 (expensive), null when unknown.
+	PriceLevel *int `json:"price_level"`
+	// CategoryIDs sets the categories on create and update, leaving it out
+	// keeps them. Categories are what the business is listed under.
+	CategoryIDs []string   `json:"category_ids,omitempty"`
+	Categories  []Category `json:"categories"`
+	// Latitude and Longitude are null until set or geocoded from the address.
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// ClearCoordinates makes update set both coordinates to null.
+	ClearCoordinates bool `json:"-"`
+	// DistanceM is only set when listing businesses near a point.
+	DistanceM *float64 `json:"distance_m,omitempty"`
+	// Highlight is only set when searching, see BusinessHighlight.
+	Highlight *BusinessHighlight `json:"highlight,omitempty"`
+	// Timezone is an IANA name like "Asia/Tashkent", opening hours are in it.
+	Timezone string `json:"timezone"`
+	// IsOpenNow and NextChangeAt are only set when getting a single business.
+	// NextChangeAt is empty when the business won't open or close within two weeks.
+	IsOpenNow    *bool  `json:"is_open_now,omitempty"`
+	NextChangeAt string `json:"next_change_at,omitempty"`
+	// RatingAvg, ReviewCount and RatingHistogram are kept up to date with the
+	// reviews and ignored on create and update. RatingHistogram counts the
+	// reviews with 1 to 5 stars.
+	RatingAvg       float64 `json:"rating_avg"`
+	ReviewCount     int     `json:"review_count"`
+	RatingHistogram []int   `json:"rating_histogram"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
 }
 
 type BusinessList struct {
 	Items []Business `json:"businesses"`
 	Count int        `json:"count"`
+	// Facets is only set when asked for.
+	Facets *BusinessFacets `json:"facets,omitempty"`
+}
+
+// BusinessFacets counts the businesses each filter value would list. A facet
+// ignores its own filter but applies all the others.
+type BusinessFacets struct {
+	Categories []CategoryFacet `json:"categories"`
+	// Ratings counts the businesses rated at least Value.
+	Ratings     []FacetCount `json:"ratings"`
+	PriceLevels []FacetCount `json:"price_levels"`
+	OpenNow     int          `json:"open_now"`
+	// Distances counts the businesses within Value meters, it is empty unless
+	// listing near a point.
+	Distances []FacetCount `json:"distances"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type CategoryFacet struct {
+	ID    string `json:"id"`
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// BusinessListFilter is GetListFilter plus the search options only businesses have.
+type BusinessListFilter struct {
+	GetListFilter
+	// Near, when set, adds distance_m to every item and sorts by it.
+	Near *GeoPoint
+	// RadiusM drops businesses further than this from Near, 0 keeps them all.
+	RadiusM float64
+	// OpenNow keeps only the businesses that are open at the moment.
+	OpenNow bool
+	// CategoryID keeps the businesses in this category or any category below it.
+	CategoryID string
+	// OrderByRating sorts by the bayesian average of the rating before OrderBy.
+	OrderByRating bool
+	// Query keeps the businesses matching the search words, misspelled names
+	// included, and adds a Highlight to every item.
+	Query string
+	// OrderByRelevance sorts by how well a business matches Query, weighted by
+	// its rating, before OrderBy.
+	OrderByRelevance bool
+	// MinRating keeps the businesses with at least this rating_avg, 0 keeps all.
+	MinRating float64
+	// PriceLevels keeps the businesses with one of these price levels.
+	PriceLevels []int
+	// FacetsLang, when set, adds facets to the list with category names in
+	// this language.
+	FacetsLang string
+}
+
+// BusinessHighlight is the name and the start of the description of a business
+// found by a search, with the matching words in <b></b>.
+type BusinessHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// BusinessMember gives a user a role in a business: owner, manager or editor.
+// A business has exactly one owner, businesses.owner_id is kept equal to it.
+type BusinessMember struct {
+	ID         string `json:"id"`
+	BusinessID string `json:"business_id"`
+	UserID     string `json:"user_id"`
+	Role       string `json:"role"`
+	Username   string `json:"username"`
+	FullName   string `json:"full_name"`
+	Email      string `json:"email"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type BusinessMemberList struct {
+	Items []BusinessMember `json:"members"`
+	Count int              `json:"count"`
+}
+
+type BusinessMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type BusinessTransferRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// BusinessInvitation is sent by email, the token in it is stored only as a hash.
+type BusinessInvitation struct {
+	ID         string `json:"id"`
+	BusinessID string `json:"business_id"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	TokenHash  string `json:"-"`
+	InvitedBy  string `json:"invited_by"`
+	Status     string `json:"status"`
+	ExpiresAt  string `json:"expires_at"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type BusinessInvitationList struct {
+	Items []BusinessInvitation `json:"invitations"`
+	Count int                  `json:"count"`
+}
+
+type BusinessInvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type AcceptBusinessInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// BusinessHourInterval is a time of day range in the business's timezone, as
+// "HH:MM". An interval whose closes_at isn't after opens_at ends on the next
+// day, "00:00"-"00:00" is open around the clock.
+type BusinessHourInterval struct {
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+}
+
+// BusinessHours is one opening interval of a weekday, a day may have several.
+type BusinessHours struct {
+	DayOfWeek int `json:"day_of_week"` // 0 is Sunday
+	BusinessHourInterval
+}
+
+// BusinessHourOverride replaces the weekly hours of one date, e.g. a holiday.
+type BusinessHourOverride struct {
+	Date      string                 `json:"date"` // 2006-01-02
+	Closed    bool                   `json:"closed"`
+	Intervals []BusinessHourInterval `json:"intervals"`
+	Note      string                 `json:"note"`
+}
+
+type BusinessSchedule struct {
+	BusinessID string                 `json:"business_id"`
+	Timezone   string                 `json:"timezone"`
+	Hours      []BusinessHours        `json:"hours"`
+	Overrides  []BusinessHourOverride `json:"overrides"` // upcoming dates only
+}
+
+type BusinessHoursRequest struct {
+	Hours []BusinessHours `json:"hours"`
+}
+
+type BusinessHourOverrideRequest struct {
+	Closed    bool                   `json:"closed"`
+	Intervals []BusinessHourInterval `json:"intervals"`
+	Note      string                 `json:"note"`
 }
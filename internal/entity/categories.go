@@ -0,0 +1,17 @@
+package entity
+
+// Category is a node of the business taxonomy, e.g. "cafes" under "food-and-drink".
+type Category struct {
+	ID       string `json:"id"`
+	Slug     string `json:"slug"`
+	ParentID string `json:"parent_id"` // empty for a top level category
+	// Names holds the display name by language code, e.g. {"en": "Cafes", "ru": "Кафе"}.
+	Names     map[string]string `json:"names"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+type CategoryList struct {
+	Items []Category `json:"categories"`
+	Count int        `json:"count"`
+}
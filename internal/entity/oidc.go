@@ -0,0 +1,22 @@
+package entity
+
+// UserIdentity links a user to an account at an OpenID Connect provider.
+type UserIdentity struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Issuer    string `json:"issuer"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type OidcLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OidcLoginState is kept in redis between the redirect to the provider and the callback.
+type OidcLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	Platform     string `json:"platform"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+// Photo is an image in the gallery of a business, a review or a user.
+type Photo struct {
+	ID         string `json:"id"`
+	OwnerType  string `json:"owner_type"` // business, review or user
+	OwnerID    string `json:"owner_id"`
+	URL        string `json:"url"`
+	ObjectKey  string `json:"-"` // name of the object in the bucket
+	Caption    string `json:"caption"`
+	UploadedBy string `json:"uploaded_by"`
+	Position   int    `json:"position"`
+	// IsCover marks the photo shown for its owner, e.g. a user's avatar. Every
+	// gallery that has photos has exactly one cover.
+	IsCover   bool   `json:"is_cover"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type PhotoList struct {
+	Items []Photo `json:"photos"`
+	Count int     `json:"count"`
+}
+
+// PhotoUpdateRequest changes only the fields that are given. A cover can't be
+// unset, make another photo the cover instead.
+type PhotoUpdateRequest struct {
+	Caption  *string `json:"caption"`
+	Position *int    `json:"position"`
+	IsCover  *bool   `json:"is_cover"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+// Policy allows a role to call the routes matching Path with the HTTP methods
+// matching Methods, a regular expression like "GET|POST".
+type Policy struct {
+	Role    string `json:"role"`
+	Path    string `json:"path"`
+	Methods string `json:"methods"`
+}
+
+type PolicyList struct {
+	Items []Policy `json:"policies"`
+	Count int      `json:"count"`
+}
+
+// RoleGrant gives Subject, a role, everything Role is allowed to do.
+type RoleGrant struct {
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+}
+
+type RoleGrantList struct {
+	Items []RoleGrant `json:"role_grants"`
+	Count int         `json:"count"`
+}
@@ -7,10 +7,93 @@ type Review struct {
 	Rating     int    `json:"rating"` // Value between 1 and 5
 	Feedback   string `json:"feedback"`
 	Photos     string `json:"photos"` // Assuming JSONB data is stored as a string
-	CreatedAt  string `json:"created_at"`
+	// Status is pending, published or rejected, only published reviews are
+	// listed and rated. Flags are why the pre-screen held the review back,
+	// ModerationNote is what the admin who decided on it wrote. All three are
+	// ignored on create and update.
+	Status         string   `json:"status"`
+	Flags          []string `json:"flags"`
+	ModerationNote string   `json:"moderation_note"`
+	// Reactions counts the reactions of other users, they are ignored on
+	// create and update.
+	Reactions ReviewReactionCounts `json:"reactions"`
+	// MyReactions are the reaction types the caller gave the review.
+	MyReactions []string `json:"my_reactions"`
+	// Reply is the answer of the business, null until a member writes one.
+	Reply *ReviewReply `json:"reply"`
+	// EditedBy is the user making a change, it is recorded in the revision.
+	EditedBy  string `json:"-"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 type ReviewList struct {
 	Items []Review `json:"reviews"`
 	Count int      `json:"count"`
 }
+
+// ReviewRevision is one version of a review, revision 1 is the review as it
+// was first posted.
+type ReviewRevision struct {
+	ID       string `json:"id"`
+	ReviewID string `json:"review_id"`
+	Revision int    `json:"revision"`
+	Rating   int    `json:"rating"`
+	Feedback string `json:"feedback"`
+	EditedBy string `json:"edited_by"`
+	// Changes compares the revision with the one before it.
+	Changes   []ReviewChange `json:"changes"`
+	CreatedAt string         `json:"created_at"`
+}
+
+type ReviewChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type ReviewRevisionList struct {
+	Items []ReviewRevision `json:"revisions"`
+	Count int              `json:"count"`
+}
+
+// ReviewReply is the public answer of the reviewed business to a review,
+// written by one of its members.
+type ReviewReply struct {
+	ID        string `json:"id"`
+	ReviewID  string `json:"review_id"`
+	AuthorID  string `json:"author_id"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ReviewReplyRequest struct {
+	Message string `json:"message"`
+}
+
+type ReviewReactionCounts struct {
+	Useful int `json:"useful"`
+	Funny  int `json:"funny"`
+	Cool   int `json:"cool"`
+}
+
+// ReviewReaction is a user finding a review useful, funny or cool. A user
+// gives each type at most once and never to their own reviews.
+type ReviewReaction struct {
+	ReviewID string `json:"review_id"`
+	UserID   string `json:"user_id"`
+	Type     string `json:"type"`
+}
+
+// ReviewModeration is an admin's decision to publish or reject a review.
+type ReviewModeration struct {
+	ReviewID    string
+	Status      string
+	ModeratedBy string
+	Note        string
+}
+
+type ReviewModerationRequest struct {
+	Note string `json:"note"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+// Suggestion is one type-ahead match, Type is business, category or event and
+// ID is the id to open it by.
+type Suggestion struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SuggestionList struct {
+	Items []Suggestion `json:"suggestions"`
+	Count int          `json:"count"`
+}
+
+type SuggestRequest struct {
+	Query string
+	// Lang picks the language of category names, english when missing.
+	Lang  string
+	Limit int
+}
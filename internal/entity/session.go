@@ -17,3 +17,21 @@ type SessionList struct {
 	Items []Session `json:"sessions"`
 	Count int       `json:"count"`//sdf
 }
+
+type Device struct {
+	SessionID      string `json:"session_id"`
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version"`
+	Platform       string `json:"platform"`
+	IPAddress      string `json:"ip_address"`
+	LastActiveAt   string `json:"last_active_at"`
+	CreatedAt      string `json:"created_at"`
+	IsCurrent      bool   `json:"is_current"`
+}
+
+type DeviceList struct {
+	Items []Device `json:"devices"`
+	Count int      `json:"count"`
+}
@@ -0,0 +1,57 @@
+package entity
+
+type UserTotp struct {
+	UserID        string   `json:"user_id"`
+	Secret        string   `json:"-"`
+	IsEnabled     bool     `json:"is_enabled"`
+	RecoveryCodes []string `json:"-"` // sha256 hashes, the plain codes are shown only once
+	LastUsedStep  int64    `json:"-"`
+	EnabledAt     string   `json:"enabled_at"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// TwoFactorChallenge is returned by login instead of a session when the
+// account has to pass a second factor first.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	SetupRequired     bool   `json:"setup_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+// TwoFactorChallengeState is what is kept in redis for a pending challenge.
+type TwoFactorChallengeState struct {
+	UserID        string `json:"user_id"`
+	Platform      string `json:"platform"`
+	SetupRequired bool   `json:"setup_required"`
+}
+
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorEnableRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorDisableRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
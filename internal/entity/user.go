@@ -1,20 +1,21 @@
 package entity
 
 type User struct {
-	ID          string `json:"id"`
-	FullName    string `json:"full_name"`
-	Username    string `json:"username"`
-	Email       string `json:"email"`
-	Password    string `json:"password"`
-	UserType    string `json:"user_type"`
-	UserRole    string `json:"user_role"`
-	Status      string `json:"status"`
-	Gender      string `json:"gender"`
-	Bio         string `json:"bio"`
-	AvatarId    string `json:"profile_picture"`
-	AccessToken string `json:"access_token"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID           string `json:"id"`
+	FullName     string `json:"full_name"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	UserType     string `json:"user_type"`
+	UserRole     string `json:"user_role"`
+	Status       string `json:"status"`
+	Gender       string `json:"gender"`
+	Bio          string `json:"bio"`
+	AvatarId     string `json:"profile_picture"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
 }
 
 type UserSingleRequest struct {
@@ -28,3 +29,17 @@ type UserList struct {
 	Items []User `json:"users"`
 	Count int    `json:"count"`
 }
+
+type UserStatusChange struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Status    string `json:"status"` // active or blocked
+	Reason    string `json:"reason"`
+	ChangedBy string `json:"changed_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type UserStatusHistory struct {
+	Items []UserStatusChange `json:"history"`
+	Count int                `json:"count"`
+}
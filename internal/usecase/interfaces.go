@@ -3,8 +3,10 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/casbin/casbin/persist"
 )
 
 //go:generate mockgen -source=interfaces.go -destination=./mocks_test.go -package=usecase_test
@@ -17,6 +19,9 @@ type (
 		GetList(ctx context.Context, req entity.GetListFilter) (entity.UserList, error)
 		Update(ctx context.Context, req entity.User) (entity.User, error)
 		Delete(ctx context.Context, req entity.Id) error
+		GetStatus(ctx context.Context, req entity.Id) (string, error)
+		UpdateStatus(ctx context.Context, req entity.UserStatusChange) (entity.UserStatusChange, error)
+		GetStatusHistory(ctx context.Context, req entity.GetListFilter) (entity.UserStatusHistory, error)
 	}
 
 	// SessionRepo -.
@@ -28,13 +33,76 @@ type (
 		Delete(ctx context.Context, req entity.Id) error
 		UpdateField(ctx context.Context, req entity.UpdateFieldRequest) (entity.RowsEffected, error)
 	}
+	RefreshTokenRepoI interface {
+		Create(ctx context.Context, req entity.RefreshToken) (entity.RefreshToken, error)
+		GetSingle(ctx context.Context, req entity.RefreshToken) (entity.RefreshToken, error)
+		MarkUsed(ctx context.Context, req entity.Id) (entity.RowsEffected, error)
+		RevokeSession(ctx context.Context, req entity.Id) (entity.RowsEffected, error)
+	}
+	TotpRepoI interface {
+		Create(ctx context.Context, req entity.UserTotp) (entity.UserTotp, error)
+		GetSingle(ctx context.Context, req entity.Id) (entity.UserTotp, error)
+		Update(ctx context.Context, req entity.UserTotp) (entity.UserTotp, error)
+		UseStep(ctx context.Context, req entity.UserTotp) (entity.RowsEffected, error)
+		UseRecoveryCode(ctx context.Context, req entity.UserTotp) (entity.RowsEffected, error)
+		Delete(ctx context.Context, req entity.Id) error
+	}
+	IdentityRepoI interface {
+		Create(ctx context.Context, req entity.UserIdentity) (entity.UserIdentity, error)
+		GetSingle(ctx context.Context, req entity.UserIdentity) (entity.UserIdentity, error)
+	}
+	LoginEventRepoI interface {
+		Create(ctx context.Context, req entity.LoginEvent) (entity.LoginEvent, error)
+		GetList(ctx context.Context, req entity.GetListFilter) (entity.LoginEventList, error)
+		IsKnownDevice(ctx context.Context, req entity.LoginEvent) (bool, bool, error)
+	}
+	// PolicyRepoI stores the casbin policy, the enforcer talks to it directly.
+	PolicyRepoI interface {
+		persist.Adapter
+	}
 	BusinessRepoI interface {
 		Create(ctx context.Context, req entity.Business) (entity.Business, error)
 		GetSingle(ctx context.Context, req entity.Id) (entity.Business, error)
-		GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessList, error)
+		GetList(ctx context.Context, req entity.BusinessListFilter) (entity.BusinessList, error)
 		Update(ctx context.Context, req entity.Business) (entity.Business, error)
 		Delete(ctx context.Context, req entity.Id) error
 	}
+	BusinessMemberRepoI interface {
+		GetSingle(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error)
+		GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessMemberList, error)
+		UpdateRole(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error)
+		Delete(ctx context.Context, req entity.BusinessMember) error
+		TransferOwnership(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error)
+	}
+	BusinessInvitationRepoI interface {
+		Create(ctx context.Context, req entity.BusinessInvitation) (entity.BusinessInvitation, error)
+		GetSingle(ctx context.Context, req entity.BusinessInvitation) (entity.BusinessInvitation, error)
+		GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessInvitationList, error)
+		Revoke(ctx context.Context, req entity.BusinessInvitation) error
+		Accept(ctx context.Context, req entity.BusinessInvitation, userID string) (entity.BusinessMember, error)
+	}
+	BusinessHoursRepoI interface {
+		GetSchedule(ctx context.Context, req entity.Id) (entity.BusinessSchedule, error)
+		SetHours(ctx context.Context, req entity.BusinessSchedule) error
+		SetOverride(ctx context.Context, businessID string, req entity.BusinessHourOverride) error
+		DeleteOverride(ctx context.Context, businessID, date string) error
+	}
+	CategoryRepoI interface {
+		Create(ctx context.Context, req entity.Category) (entity.Category, error)
+		GetSingle(ctx context.Context, req entity.Category) (entity.Category, error)
+		GetList(ctx context.Context, req entity.GetListFilter) (entity.CategoryList, error)
+		GetSubtreeIDs(ctx context.Context, req entity.Id) ([]string, error)
+		Update(ctx context.Context, req entity.Category) (entity.Category, error)
+		Delete(ctx context.Context, req entity.Id) error
+	}
+	PhotoRepoI interface {
+		Create(ctx context.Context, req entity.Photo) (entity.Photo, error)
+		GetSingle(ctx context.Context, req entity.Id) (entity.Photo, error)
+		GetList(ctx context.Context, req entity.GetListFilter) (entity.PhotoList, error)
+		Update(ctx context.Context, req entity.Photo) (entity.Photo, error)
+		Delete(ctx context.Context, req entity.Id) (entity.Photo, error)
+		GetObjectKeys(ctx context.Context, req entity.Photo) ([]string, error)
+	}
 	NotificationRepoI interface {
 		Create(ctx context.Context, req entity.Notification) (entity.Notification, error)
 		GetSingle(ctx context.Context, req entity.Id) (entity.Notification, error)
@@ -49,6 +117,18 @@ type (
 		GetList(ctx context.Context, req entity.GetListFilter) (entity.ReviewList, error)
 		Update(ctx context.Context, req entity.Review) (entity.Review, error)
 		Delete(ctx context.Context, req entity.Id) error
+		GetRevisions(ctx context.Context, req entity.Id, filter entity.GetListFilter) (entity.ReviewRevisionList, error)
+		Moderate(ctx context.Context, req entity.ReviewModeration) (entity.Review, error)
+	}
+	ReviewReactionRepoI interface {
+		Create(ctx context.Context, req entity.ReviewReaction) error
+		Delete(ctx context.Context, req entity.ReviewReaction) error
+		GetTypes(ctx context.Context, userID string, reviewIDs ...string) (map[string][]string, error)
+	}
+	ReviewReplyRepoI interface {
+		Set(ctx context.Context, req entity.ReviewReply) (entity.ReviewReply, bool, error)
+		GetSingle(ctx context.Context, req entity.Id) (entity.ReviewReply, error)
+		Delete(ctx context.Context, req entity.Id) error
 	}
 	ReportRepoI interface {
 		Create(ctx context.Context, req entity.Report) (entity.Report, error)
@@ -67,4 +147,12 @@ type (
 		RemoveParticipant(ctx context.Context, req entity.EventParticipant) error
 		GetParticipants(ctx context.Context, req entity.GetListFilter) (entity.EventParticipantList, error)
 	}
+	SearchRepoI interface {
+		Suggest(ctx context.Context, req entity.SuggestRequest) (entity.SuggestionList, error)
+	}
+	AttemptRepoI interface {
+		Increment(ctx context.Context, key string, ttl time.Duration) (int, error)
+		Get(ctx context.Context, key string) (int, error)
+		Reset(ctx context.Context, key string) error
+	}
 )
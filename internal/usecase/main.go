@@ -5,28 +5,57 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/internal/usecase/repo"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	rediscache "github.com/golanguzb70/redis-cache"
 )
 
 // UseCase -.
 type UseCase struct {
 	UserRepo    UserRepoI
 	SessionRepo SessionRepoI
+	RefreshTokenRepo RefreshTokenRepoI
+	TotpRepo TotpRepoI
+	IdentityRepo IdentityRepoI
+	LoginEventRepo LoginEventRepoI
+	PolicyRepo PolicyRepoI
 	BusinessRepo BusinessRepoI
+	BusinessMemberRepo BusinessMemberRepoI
+	BusinessInvitationRepo BusinessInvitationRepoI
+	BusinessHoursRepo BusinessHoursRepoI
+	CategoryRepo CategoryRepoI
+	PhotoRepo PhotoRepoI
 	NotificationRepo NotificationRepoI
 	ReviewRepo ReviewRepoI
+	ReviewReplyRepo ReviewReplyRepoI
+	ReviewReactionRepo ReviewReactionRepoI
 	ReportRepo ReportRepoI
 	EventRepo EventRepoI
+	SearchRepo SearchRepoI
+	AttemptRepo AttemptRepoI
 }
 
 // New -.
-func New(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *UseCase {
+func New(pg *postgres.Postgres, config *config.Config, logger *logger.Logger, redis rediscache.RedisCache) *UseCase {
 	return &UseCase{
-		UserRepo:    repo.NewUserRepo(pg, config, logger),
-		SessionRepo: repo.NewSessionRepo(pg, config, logger),
+		UserRepo:    repo.NewUserRepo(pg, config, logger, redis),
+		SessionRepo: repo.NewSessionRepo(pg, config, logger, redis),
+		RefreshTokenRepo: repo.NewRefreshTokenRepo(pg, config, logger),
+		TotpRepo: repo.NewTotpRepo(pg, config, logger),
+		IdentityRepo: repo.NewIdentityRepo(pg, config, logger),
+		LoginEventRepo: repo.NewLoginEventRepo(pg, config, logger),
+		PolicyRepo: repo.NewPolicyRepo(pg, config, logger),
 		BusinessRepo: repo.NewBusinessRepo(pg, config, logger),
+		BusinessMemberRepo: repo.NewBusinessMemberRepo(pg, config, logger),
+		BusinessInvitationRepo: repo.NewBusinessInvitationRepo(pg, config, logger),
+		BusinessHoursRepo: repo.NewBusinessHoursRepo(pg, config, logger),
+		CategoryRepo: repo.NewCategoryRepo(pg, config, logger),
+		PhotoRepo: repo.NewPhotoRepo(pg, config, logger),
 		NotificationRepo: repo.NewNotificationRepo(pg, config, logger),
 		ReviewRepo: repo.NewReviewRepo(pg, config, logger),
+		ReviewReplyRepo: repo.NewReviewReplyRepo(pg, config, logger),
+		ReviewReactionRepo: repo.NewReviewReactionRepo(pg, config, logger),
 		ReportRepo: repo.NewReportRepo(pg, config, logger),
 		EventRepo: repo.NewEventRepo(pg, config, logger),
+		SearchRepo: repo.NewSearchRepo(pg, config, logger, redis),
+		AttemptRepo: repo.NewAttemptRepo(pg, config, logger),
 	}
 }
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+)
+
+// AttemptRepo counts failed attempts, like wrong otps or passwords, under a
+// key. Counting is done by a single statement, so concurrent failures can't
+// overwrite each other and get more guesses than allowed.
+type AttemptRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewAttemptRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *AttemptRepo {
+	return &AttemptRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Increment adds a failure to the counter under key and returns the new count.
+// The counter is forgotten ttl after the last failure.
+func (r *AttemptRepo) Increment(ctx context.Context, key string, ttl time.Duration) (int, error) {
+	query, args, err := r.pg.Builder.Insert("attempts").
+		Columns(`key, attempts, expires_at`).
+		Values(key, 1, squirrel.Expr("now() + ?::interval", fmt.Sprintf("%d seconds", int(ttl.Seconds())))).
+		Suffix(`ON CONFLICT (key) DO UPDATE SET
+				attempts = CASE WHEN attempts.expires_at <= now() THEN 1 ELSE attempts.attempts + 1 END,
+				expires_at = EXCLUDED.expires_at
+			RETURNING attempts`).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts int
+	err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(&attempts)
+	if err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// Get returns the count under key, 0 when there is none or it has expired.
+func (r *AttemptRepo) Get(ctx context.Context, key string) (int, error) {
+	query, args, err := r.pg.Builder.Select("attempts").
+		From("attempts").
+		Where("key = ? AND expires_at > now()", key).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts int
+	err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// Reset forgets the count under key, along with any expired counters.
+func (r *AttemptRepo) Reset(ctx context.Context, key string) error {
+	query, args, err := r.pg.Builder.Delete("attempts").
+		Where("key = ? OR expires_at <= now()", key).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	return err
+}
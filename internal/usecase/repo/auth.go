@@ -1,5 +1,134 @@
 package repo
 
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+)
 
+type RefreshTokenRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
 
+// New -.
+func NewRefreshTokenRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *RefreshTokenRepo {
+	return &RefreshTokenRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *RefreshTokenRepo) Create(ctx context.Context, req entity.RefreshToken) (entity.RefreshToken, error) {
+	req.ID = uuid.NewString()
+	expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+	if err != nil {
+		return entity.RefreshToken{}, fmt.Errorf("invalid expires_at: %w", err)
+	}
+
+	query, args, err := r.pg.Builder.Insert("refresh_tokens").
+		Columns(`id, session_id, token_hash, expires_at`).
+		Values(req.ID, req.SessionID, req.TokenHash, expiresAt).ToSql()
+	if err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	return req, nil
+}
+
+// GetSingle looks a refresh token up by its id or by the hash of the token.
+func (r *RefreshTokenRepo) GetSingle(ctx context.Context, req entity.RefreshToken) (entity.RefreshToken, error) {
+	response := entity.RefreshToken{}
+	var expiresAt, createdAt sql.NullTime
+
+	queryBuilder := r.pg.Builder.
+		Select(`id, session_id, token_hash, is_used, is_revoked, expires_at, created_at`).
+		From("refresh_tokens")
+
+	switch {
+	case req.ID != "":
+		queryBuilder = queryBuilder.Where("id = ?", req.ID)
+	case req.TokenHash != "":
+		queryBuilder = queryBuilder.Where("token_hash = ?", req.TokenHash)
+	default:
+		return entity.RefreshToken{}, fmt.Errorf("GetSingle - invalid request")
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).
+		Scan(&response.ID, &response.SessionID, &response.TokenHash, &response.IsUsed,
+			&response.IsRevoked, &expiresAt, &createdAt)
+	if err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	if expiresAt.Valid {
+		response.ExpiresAt = expiresAt.Time.Format(time.RFC3339)
+	}
+	if createdAt.Valid {
+		response.CreatedAt = createdAt.Time.Format(time.RFC3339)
+	}
+
+	return response, nil
+}
+
+// MarkUsed flags an unused token as used. RowsEffected is 0 when the token
+// had already been used, which means it is being replayed.
+func (r *RefreshTokenRepo) MarkUsed(ctx context.Context, req entity.Id) (entity.RowsEffected, error) {
+	response := entity.RowsEffected{}
+
+	query, args, err := r.pg.Builder.Update("refresh_tokens").
+		Set("is_used", true).
+		Where("id = ? AND is_used = false AND is_revoked = false", req.ID).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+
+	response.RowsEffected = int(n.RowsAffected())
+
+	return response, nil
+}
+
+// RevokeSession revokes every refresh token issued for the given session.
+func (r *RefreshTokenRepo) RevokeSession(ctx context.Context, req entity.Id) (entity.RowsEffected, error) {
+	response := entity.RowsEffected{}
+
+	query, args, err := r.pg.Builder.Update("refresh_tokens").
+		Set("is_revoked", true).
+		Where("session_id = ?", req.ID).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+
+	response.RowsEffected = int(n.RowsAffected())
+
+	return response, nil
+}
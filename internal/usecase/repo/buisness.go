@@ -2,15 +2,20 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/geo"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 )
 
 type BusinessRepo struct {
@@ -30,19 +35,47 @@ func NewBusinessRepo(pg *postgres.Postgres, config *config.Config, logger *logge
 func (r *BusinessRepo) Create(ctx context.Context, req entity.Business) (entity.Business, error) {
 	req.ID = uuid.NewString()
 
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Business{}, err
+	}
+	defer tx.Rollback(ctx)
+
 	query, args, err := r.pg.Builder.Insert("businesses").
-		Columns(`id, owner_id, name, description, category, address, contact_info, photos`).
-		Values(req.ID, req.OwnerID, req.Name, req.Description, req.Category, req.Address, req.ContactInfo, req.Photos).ToSql()
+		Columns(`id, owner_id, name, description, address, contact_info, photos, latitude, longitude, timezone, price_level`).
+		Values(req.ID, req.OwnerID, req.Name, req.Description, req.Address, req.ContactInfo, req.Photos,
+			req.Latitude, req.Longitude, req.Timezone, req.PriceLevel).ToSql()
 	if err != nil {
 		return entity.Business{}, err
 	}
 
-	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		return entity.Business{}, err
 	}
 
-	return req, nil
+	// the creator becomes the owner member
+	query, args, err = r.pg.Builder.Insert("business_members").
+		Columns(`id, business_id, user_id, role`).
+		Values(uuid.NewString(), req.ID, req.OwnerID, "owner").ToSql()
+	if err != nil {
+		return entity.Business{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Business{}, err
+	}
+
+	if err = r.setCategories(ctx, tx, req.ID, req.CategoryIDs); err != nil {
+		return entity.Business{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Business{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.ID})
 }
 
 func (r *BusinessRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Business, error) {
@@ -52,7 +85,8 @@ func (r *BusinessRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Bus
 	)
 
 	queryBuilder := r.pg.Builder.
-		Select(`id, owner_id, name, description, category, address, contact_info, photos, created_at, updated_at`).
+		Select(`id, owner_id, name, description, address, contact_info, photos, latitude, longitude, timezone,
+			price_level, review_count, rating_avg::float8, rating_histogram, created_at, updated_at`).
 		From("businesses")
 
 	switch {
@@ -69,105 +103,337 @@ func (r *BusinessRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Bus
 	}
 
 	err = r.pg.Pool.QueryRow(ctx, query, args...).
-		Scan(&response.ID, &response.OwnerID, &response.Name, &response.Description, &response.Category,
-			&response.Address, &response.ContactInfo, &response.Photos, &createdAt, &updatedAt)
+		Scan(&response.ID, &response.OwnerID, &response.Name, &response.Description,
+			&response.Address, &response.ContactInfo, &response.Photos, &response.Latitude, &response.Longitude,
+			&response.Timezone, &response.PriceLevel, &response.ReviewCount, &response.RatingAvg, &response.RatingHistogram,
+			&createdAt, &updatedAt)
 	if err != nil {
 		return entity.Business{}, err
 	}
 
 	response.CreatedAt = createdAt.Format(time.RFC3339)
 	response.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	categories, err := r.getCategories(ctx, response.ID)
+	if err != nil {
+		return entity.Business{}, err
+	}
+	response.Categories = categories[response.ID]
+
 	return response, nil
 }
 
-func (r *BusinessRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessList, error) {
+// haversineSQL is the distance in meters between a business and the point
+// given by its three arguments: latitude, latitude, longitude.
+const haversineSQL = `2 * 6371000 * asin(least(1, sqrt(power(sin(radians(latitude - ?) / 2), 2) + ` +
+	`cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2))))`
+
+// bayesianRatingSQL is the rating average of a business with as many reviews
+// as its two arguments rating the average of all reviews added.
+const bayesianRatingSQL = `(? * (SELECT COALESCE(AVG(rating), 0) FROM reviews WHERE status = 'published') + rating_avg * review_count) / (? + review_count)`
+
+// searchRankSQL is how well a business matches the search words given as its
+// first two arguments, its last two are those of bayesianRatingSQL. Matching
+// text counts by where it is found, name first, and a name can also match by
+// trigram similarity. A 5 star business ranks a third above a 0 star one that
+// matches as well.
+const searchRankSQL = `(ts_rank_cd(search_vector, websearch_to_tsquery('simple', ?), 32) + word_similarity(?, name)) * ` +
+	`(0.75 + 0.05 * ` + bayesianRatingSQL + `)`
+
+// headlineOptions keep highlights short enough for a list.
+const headlineOptions = "StartSel=<b>, StopSel=</b>, MaxWords=30, MinWords=10, MaxFragments=2"
+
+func (r *BusinessRepo) GetList(ctx context.Context, req entity.BusinessListFilter) (entity.BusinessList, error) {
 	var response = entity.BusinessList{}
-	var createdAt, updatedAt time.Time
+	var (
+		createdAt, updatedAt time.Time
+		distance             sql.NullFloat64
+		nameHighlight        sql.NullString
+		descriptionHighlight sql.NullString
+	)
 
-	// Start building the SQL query
 	queryBuilder := r.pg.Builder.
-		Select(`id, owner_id, name, description, category, address, contact_info, photos, created_at, updated_at`).
+		Select(`id, owner_id, name, description, address, contact_info, photos, latitude, longitude, timezone,
+			price_level, review_count, rating_avg::float8, rating_histogram, created_at, updated_at`).
 		From("businesses")
 
-	// Apply filters (if any)
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			if filter.Column == "owner_id" {
-				if filter.Type == "eq" && filter.Value != "" {
-					// Add filter for owner_id
-					queryBuilder = queryBuilder.Where("owner_id = ?", filter.Value)
-				}
-				if filter.Type == "eq" && filter.Value == "" {
-					// If owner_id is empty, return all businesses
-					break
-				}
-			}
-		}
+	where := r.listWhere(req)
+
+	if req.Near != nil {
+		lat, lng := req.Near.Latitude, req.Near.Longitude
+		queryBuilder = queryBuilder.Column(squirrel.Expr(haversineSQL+" AS distance_m", lat, lat, lng))
+	} else {
+		queryBuilder = queryBuilder.Column("NULL::float8 AS distance_m")
 	}
 
-	// Apply pagination (LIMIT and OFFSET)
-	if req.Limit > 0 {
-		queryBuilder = queryBuilder.Limit(uint64(req.Limit))
+	if req.Query != "" {
+		queryBuilder = queryBuilder.
+			Column(squirrel.Expr("ts_headline('simple', name, websearch_to_tsquery('simple', ?), ?)", req.Query, headlineOptions)).
+			Column(squirrel.Expr("ts_headline('simple', COALESCE(description, ''), websearch_to_tsquery('simple', ?), ?)", req.Query, headlineOptions))
+	} else {
+		queryBuilder = queryBuilder.Columns("NULL::text", "NULL::text")
 	}
-	if req.Page > 0 {
-		offset := (req.Page - 1) * req.Limit
-		queryBuilder = queryBuilder.Offset(uint64(offset))
+
+	if req.OrderByRelevance && req.Query != "" {
+		queryBuilder = queryBuilder.OrderByClause(searchRankSQL+" DESC", req.Query, req.Query,
+			config.BusinessRatingPriorWeight, config.BusinessRatingPriorWeight)
+	}
+
+	// businesses with few reviews are pulled towards the average of all
+	// reviews, so a single 5 star review doesn't put a business on top
+	if req.OrderByRating {
+		queryBuilder = queryBuilder.OrderByClause(bayesianRatingSQL+" DESC", config.BusinessRatingPriorWeight, config.BusinessRatingPriorWeight)
 	}
 
-	// Prepare the SQL query for fetching businesses
+	// the filters are already in where, PrepareGetListQuery only adds the order and page
+	listFilter := req.GetListFilter
+	listFilter.Filters = nil
+	queryBuilder, _ = PrepareGetListQuery(queryBuilder.Where(where), listFilter)
+
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
 		return response, err
 	}
 
-	// Execute the query for businesses
 	rows, err := r.pg.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return response, err
 	}
 	defer rows.Close()
 
-	// Scan the business records into response.Items
 	for rows.Next() {
 		var item entity.Business
-		err = rows.Scan(&item.ID, &item.OwnerID, &item.Name, &item.Description, &item.Category,
-			&item.Address, &item.ContactInfo, &item.Photos, &createdAt, &updatedAt)
+		err = rows.Scan(&item.ID, &item.OwnerID, &item.Name, &item.Description,
+			&item.Address, &item.ContactInfo, &item.Photos, &item.Latitude, &item.Longitude,
+			&item.Timezone, &item.PriceLevel, &item.ReviewCount, &item.RatingAvg, &item.RatingHistogram,
+			&createdAt, &updatedAt, &distance, &nameHighlight, &descriptionHighlight)
 		if err != nil {
 			return response, err
 		}
 
+		if distance.Valid {
+			distanceM := distance.Float64
+			item.DistanceM = &distanceM
+		}
+		if nameHighlight.Valid {
+			item.Highlight = &entity.BusinessHighlight{
+				Name:        nameHighlight.String,
+				Description: descriptionHighlight.String,
+			}
+		}
 		item.CreatedAt = createdAt.Format(time.RFC3339)
 		item.UpdatedAt = updatedAt.Format(time.RFC3339)
 
 		response.Items = append(response.Items, item)
 	}
+	rows.Close()
 
-	// Get the total count of businesses based on filters (if any)
-	countQueryBuilder := r.pg.Builder.Select("COUNT(1)").From("businesses")
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			if filter.Column == "owner_id" && filter.Type == "eq" && filter.Value != "" {
-				// Add the same filter to the COUNT query
-				countQueryBuilder = countQueryBuilder.Where("owner_id = ?", filter.Value)
-			}
-		}
+	ids := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		ids = append(ids, item.ID)
+	}
+	categories, err := r.getCategories(ctx, ids...)
+	if err != nil {
+		return response, err
+	}
+	for i := range response.Items {
+		response.Items[i].Categories = categories[response.Items[i].ID]
 	}
 
-	// Prepare and execute the COUNT query
-	countQuery, args, err := countQueryBuilder.ToSql()
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("businesses").Where(where).ToSql()
 	if err != nil {
 		return response, err
 	}
 
-	// Execute the count query
 	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
 	if err != nil {
 		return response, err
 	}
 
+	if req.FacetsLang != "" {
+		response.Facets, err = r.getFacets(ctx, req, req.FacetsLang)
+		if err != nil {
+			return response, err
+		}
+	}
+
 	return response, nil
 }
 
+// listWhere is the condition of every filter of req.
+func (r *BusinessRepo) listWhere(req entity.BusinessListFilter) squirrel.And {
+	where := PrepareFilter(req.Filters)
+
+	if req.Near != nil && req.RadiusM > 0 {
+		lat, lng := req.Near.Latitude, req.Near.Longitude
+		if minLat, maxLat, minLng, maxLng, ok := geo.BoundingBox(lat, lng, req.RadiusM); ok {
+			where = append(where,
+				squirrel.Expr("latitude BETWEEN ? AND ?", minLat, maxLat),
+				squirrel.Expr("longitude BETWEEN ? AND ?", minLng, maxLng))
+		}
+		where = append(where, squirrel.Expr(haversineSQL+" <= ?", lat, lat, lng, req.RadiusM))
+	}
+
+	if req.Query != "" {
+		where = append(where, squirrel.Expr("(search_vector @@ websearch_to_tsquery('simple', ?) OR ? <% name)", req.Query, req.Query))
+	}
+
+	if req.CategoryID != "" {
+		where = append(where, squirrel.Expr("EXISTS ("+categorySubtreeCTE+
+			" SELECT 1 FROM business_categories bc JOIN subtree s ON s.id = bc.category_id WHERE bc.business_id = businesses.id)",
+			req.CategoryID))
+	}
+
+	if req.OpenNow {
+		where = append(where, squirrel.Expr("business_is_open(id, timezone, now())"))
+	}
+
+	if req.MinRating > 0 {
+		where = append(where, squirrel.Expr("rating_avg >= ?", req.MinRating))
+	}
+
+	if len(req.PriceLevels) > 0 {
+		where = append(where, squirrel.Eq{"price_level": req.PriceLevels})
+	}
+
+	return where
+}
+
+// getFacets counts the businesses matching req by category, minimum rating,
+// price level, being open and distance. Every facet leaves its own filter out
+// of req, so its counts show what choosing another value would give.
+func (r *BusinessRepo) getFacets(ctx context.Context, req entity.BusinessListFilter, lang string) (*entity.BusinessFacets, error) {
+	facets := &entity.BusinessFacets{
+		Categories:  []entity.CategoryFacet{},
+		Ratings:     []entity.FacetCount{},
+		PriceLevels: []entity.FacetCount{},
+		Distances:   []entity.FacetCount{},
+	}
+
+	// categories, counting the businesses under each category or below it
+	// like the category filter does, once even when they are in several of
+	// its subcategories
+	without := req
+	without.CategoryID = ""
+	businesses := squirrel.Select("id").From("businesses").Where(r.listWhere(without))
+
+	query, args, err := r.pg.Builder.
+		Select().
+		Prefix(categoryAncestorsCTE).
+		Column(`c.id, c.slug, COALESCE(c.names->>?::text, c.names->>'en'), COUNT(DISTINCT bc.business_id)`, lang).
+		From("business_categories bc").
+		Join("ancestors a ON a.category_id = bc.category_id").
+		Join("categories c ON c.id = a.ancestor_id").
+		Where(businesses.Prefix("bc.business_id IN (").Suffix(")")).
+		GroupBy("c.id").
+		OrderBy("4 DESC", "c.slug").
+		Limit(uint64(config.BusinessCategoryFacetLimit)).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.CategoryFacet
+		if err = rows.Scan(&item.ID, &item.Slug, &item.Name, &item.Count); err != nil {
+			return nil, err
+		}
+		facets.Categories = append(facets.Categories, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// ratings, each bucket counts the businesses rated at least its value
+	without = req
+	without.MinRating = 0
+	counts := r.pg.Builder.Select().From("businesses").Where(r.listWhere(without))
+	for _, rating := range config.BusinessRatingFacets {
+		counts = counts.Column(squirrel.Expr("COUNT(1) FILTER (WHERE rating_avg >= ?)", rating))
+	}
+	values := make([]string, 0, len(config.BusinessRatingFacets))
+	for _, rating := range config.BusinessRatingFacets {
+		values = append(values, strconv.FormatFloat(rating, 'f', -1, 64))
+	}
+	if facets.Ratings, err = r.countFacets(ctx, counts, values); err != nil {
+		return nil, err
+	}
+
+	// price levels
+	without = req
+	without.PriceLevels = nil
+	counts = r.pg.Builder.Select().From("businesses").Where(r.listWhere(without))
+	values = values[:0]
+	for level := 1; level <= config.BusinessMaxPriceLevel; level++ {
+		counts = counts.Column(squirrel.Expr("COUNT(1) FILTER (WHERE price_level = ?)", level))
+		values = append(values, strconv.Itoa(level))
+	}
+	if facets.PriceLevels, err = r.countFacets(ctx, counts, values); err != nil {
+		return nil, err
+	}
+
+	// open now
+	without = req
+	without.OpenNow = false
+	query, args, err = r.pg.Builder.Select("COUNT(1) FILTER (WHERE business_is_open(id, timezone, now()))").
+		From("businesses").Where(r.listWhere(without)).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(&facets.OpenNow); err != nil {
+		return nil, err
+	}
+
+	// distances, only around a point, each band counts the businesses within it
+	if req.Near != nil {
+		lat, lng := req.Near.Latitude, req.Near.Longitude
+		without = req
+		without.RadiusM = 0
+		counts = r.pg.Builder.Select().From("businesses").Where(r.listWhere(without))
+		values = values[:0]
+		for _, radius := range config.BusinessDistanceFacets {
+			counts = counts.Column(squirrel.Expr("COUNT(1) FILTER (WHERE "+haversineSQL+" <= ?)", lat, lat, lng, radius))
+			values = append(values, strconv.FormatFloat(radius, 'f', -1, 64))
+		}
+		if facets.Distances, err = r.countFacets(ctx, counts, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+// countFacets runs a query of one count column per value.
+func (r *BusinessRepo) countFacets(ctx context.Context, counts squirrel.SelectBuilder, values []string) ([]entity.FacetCount, error) {
+	query, args, err := counts.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]int, len(values))
+	dest := make([]interface{}, len(values))
+	for i := range result {
+		dest[i] = &result[i]
+	}
+
+	if err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	facets := make([]entity.FacetCount, 0, len(values))
+	for i, value := range values {
+		facets = append(facets, entity.FacetCount{Value: value, Count: result[i]})
+	}
+
+	return facets, nil
+}
+
 func (r *BusinessRepo) Update(ctx context.Context, req entity.Business) (entity.Business, error) {
 	mp := make(map[string]interface{})
 
@@ -177,17 +443,26 @@ func (r *BusinessRepo) Update(ctx context.Context, req entity.Business) (entity.
 	if req.Description != "" {
 		mp["description"] = req.Description
 	}
-	if req.Category != "" {
-		mp["category"] = req.Category
-	}
 	if req.Address != "" {
 		mp["address"] = req.Address
 	}
 	if req.ContactInfo != "" {
 		mp["contact_info"] = req.ContactInfo
 	}
-	if req.Photos != "" {
-		mp["photos"] = req.Photos
+	if req.Latitude != nil && req.Longitude != nil {
+		mp["latitude"] = *req.Latitude
+		mp["longitude"] = *req.Longitude
+	} else if req.ClearCoordinates {
+		mp["latitude"] = nil
+		mp["longitude"] = nil
+	}
+
+	if req.Timezone != "" {
+		mp["timezone"] = req.Timezone
+	}
+
+	if req.PriceLevel != nil {
+		mp["price_level"] = *req.PriceLevel
 	}
 
 	mp["updated_at"] = "now()"
@@ -196,16 +471,32 @@ func (r *BusinessRepo) Update(ctx context.Context, req entity.Business) (entity.
 		return entity.Business{}, errors.New("no fields to update")
 	}
 
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Business{}, err
+	}
+	defer tx.Rollback(ctx)
+
 	query, args, err := r.pg.Builder.Update("businesses").SetMap(mp).Where("id = ?", req.ID).ToSql()
 	if err != nil {
 		return entity.Business{}, err
 	}
 
-	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		return entity.Business{}, err
 	}
 
+	if req.CategoryIDs != nil {
+		if err = r.setCategories(ctx, tx, req.ID, req.CategoryIDs); err != nil {
+			return entity.Business{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Business{}, err
+	}
+
 	res, err := r.GetSingle(ctx, entity.Id{ID: req.ID})
 	if err != nil {
 		return entity.Business{}, err
@@ -227,3 +518,78 @@ func (r *BusinessRepo) Delete(ctx context.Context, req entity.Id) error {
 
 	return nil
 }
+
+// setCategories replaces the categories of a business.
+func (r *BusinessRepo) setCategories(ctx context.Context, tx pgx.Tx, businessID string, categoryIDs []string) error {
+	query, args, err := r.pg.Builder.Delete("business_categories").Where("business_id = ?", businessID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	insert := r.pg.Builder.Insert("business_categories").Columns(`business_id, category_id`)
+	for _, id := range categoryIDs {
+		insert = insert.Values(businessID, id)
+	}
+
+	query, args, err = insert.Suffix("ON CONFLICT DO NOTHING").ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// getCategories returns the categories of the given businesses by business id.
+func (r *BusinessRepo) getCategories(ctx context.Context, businessIDs ...string) (map[string][]entity.Category, error) {
+	response := make(map[string][]entity.Category, len(businessIDs))
+	for _, id := range businessIDs {
+		response[id] = []entity.Category{}
+	}
+	if len(businessIDs) == 0 {
+		return response, nil
+	}
+
+	query, args, err := r.pg.Builder.
+		Select(`bc.business_id, c.id, c.slug, COALESCE(c.parent_id::text, ''), c.names, c.created_at, c.updated_at`).
+		From("business_categories bc").
+		Join("categories c ON c.id = bc.category_id").
+		Where(squirrel.Eq{"bc.business_id": businessIDs}).
+		OrderBy("c.slug").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			businessID           string
+			item                 entity.Category
+			createdAt, updatedAt time.Time
+		)
+		err = rows.Scan(&businessID, &item.ID, &item.Slug, &item.ParentID, &item.Names, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+		item.UpdatedAt = updatedAt.Format(time.RFC3339)
+		response[businessID] = append(response[businessID], item)
+	}
+
+	return response, rows.Err()
+}
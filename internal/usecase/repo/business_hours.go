@@ -0,0 +1,222 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/hours"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type BusinessHoursRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewBusinessHoursRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *BusinessHoursRepo {
+	return &BusinessHoursRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// GetSchedule returns the timezone and weekly hours of a business with its
+// overrides from yesterday on, older ones can't affect whether it's open.
+func (r *BusinessHoursRepo) GetSchedule(ctx context.Context, req entity.Id) (entity.BusinessSchedule, error) {
+	response := entity.BusinessSchedule{
+		BusinessID: req.ID,
+		Hours:      []entity.BusinessHours{},
+		Overrides:  []entity.BusinessHourOverride{},
+	}
+
+	query, args, err := r.pg.Builder.Select("timezone").From("businesses").Where("id = ?", req.ID).ToSql()
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(&response.Timezone)
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+
+	query, args, err = r.pg.Builder.
+		Select(`day_of_week, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')`).
+		From("business_hours").
+		Where("business_id = ?", req.ID).
+		OrderBy("day_of_week", "opens_at").ToSql()
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.BusinessHours
+		err = rows.Scan(&item.DayOfWeek, &item.OpensAt, &item.ClosesAt)
+		if err != nil {
+			return entity.BusinessSchedule{}, err
+		}
+
+		response.Hours = append(response.Hours, item)
+	}
+	rows.Close()
+
+	query, args, err = r.pg.Builder.
+		Select(`date, COALESCE(to_char(opens_at, 'HH24:MI'), ''), COALESCE(to_char(closes_at, 'HH24:MI'), ''), note`).
+		From("business_hour_overrides").
+		Where("business_id = ? AND date >= CURRENT_DATE - 1", req.ID).
+		OrderBy("date", "opens_at").ToSql()
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+
+	rows, err = r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessSchedule{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			date     time.Time
+			interval entity.BusinessHourInterval
+			note     string
+		)
+		err = rows.Scan(&date, &interval.OpensAt, &interval.ClosesAt, &note)
+		if err != nil {
+			return entity.BusinessSchedule{}, err
+		}
+
+		// the rows of a date are next to each other
+		day := date.Format(hours.DateLayout)
+		n := len(response.Overrides)
+		if n == 0 || response.Overrides[n-1].Date != day {
+			response.Overrides = append(response.Overrides, entity.BusinessHourOverride{
+				Date:      day,
+				Intervals: []entity.BusinessHourInterval{},
+				Note:      note,
+			})
+			n++
+		}
+
+		if interval.OpensAt == "" {
+			response.Overrides[n-1].Closed = true
+		} else {
+			response.Overrides[n-1].Intervals = append(response.Overrides[n-1].Intervals, interval)
+		}
+	}
+
+	return response, nil
+}
+
+// SetHours replaces all weekly hours of req.BusinessID with req.Hours.
+func (r *BusinessHoursRepo) SetHours(ctx context.Context, req entity.BusinessSchedule) error {
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := r.pg.Builder.Delete("business_hours").Where("business_id = ?", req.BusinessID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Hours) > 0 {
+		insert := r.pg.Builder.Insert("business_hours").
+			Columns(`id, business_id, day_of_week, opens_at, closes_at`)
+		for _, h := range req.Hours {
+			insert = insert.Values(uuid.NewString(), req.BusinessID, h.DayOfWeek, h.OpensAt, h.ClosesAt)
+		}
+
+		query, args, err = insert.ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetOverride replaces the hours of businessID on req.Date, a closed override
+// is stored as a single row without times.
+func (r *BusinessHoursRepo) SetOverride(ctx context.Context, businessID string, req entity.BusinessHourOverride) error {
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := r.pg.Builder.Delete("business_hour_overrides").
+		Where("business_id = ? AND date = ?", businessID, req.Date).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	insert := r.pg.Builder.Insert("business_hour_overrides").
+		Columns(`id, business_id, date, opens_at, closes_at, note`)
+	if req.Closed {
+		insert = insert.Values(uuid.NewString(), businessID, req.Date, nil, nil, req.Note)
+	} else {
+		for _, interval := range req.Intervals {
+			insert = insert.Values(uuid.NewString(), businessID, req.Date, interval.OpensAt, interval.ClosesAt, req.Note)
+		}
+	}
+
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteOverride puts businessID back on its weekly hours on date.
+func (r *BusinessHoursRepo) DeleteOverride(ctx context.Context, businessID, date string) error {
+	query, args, err := r.pg.Builder.Delete("business_hour_overrides").
+		Where("business_id = ? AND date = ?", businessID, date).ToSql()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
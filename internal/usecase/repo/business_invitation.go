@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type BusinessInvitationRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewBusinessInvitationRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *BusinessInvitationRepo {
+	return &BusinessInvitationRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *BusinessInvitationRepo) Create(ctx context.Context, req entity.BusinessInvitation) (entity.BusinessInvitation, error) {
+	req.ID = uuid.NewString()
+	req.Status = "pending"
+	expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+	if err != nil {
+		return entity.BusinessInvitation{}, fmt.Errorf("invalid expires_at: %w", err)
+	}
+
+	query, args, err := r.pg.Builder.Insert("business_invitations").
+		Columns(`id, business_id, email, role, token_hash, invited_by, status, expires_at`).
+		Values(req.ID, req.BusinessID, req.Email, req.Role, req.TokenHash, req.InvitedBy, req.Status, expiresAt).ToSql()
+	if err != nil {
+		return entity.BusinessInvitation{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessInvitation{}, err
+	}
+
+	req.CreatedAt = time.Now().Format(time.RFC3339)
+	req.UpdatedAt = req.CreatedAt
+	return req, nil
+}
+
+// GetSingle looks an invitation up by its id or by the hash of its token.
+func (r *BusinessInvitationRepo) GetSingle(ctx context.Context, req entity.BusinessInvitation) (entity.BusinessInvitation, error) {
+	queryBuilder := r.pg.Builder.
+		Select(`id, business_id, email, role, token_hash, COALESCE(invited_by::text, ''), status, expires_at, created_at, updated_at`).
+		From("business_invitations")
+
+	switch {
+	case req.ID != "":
+		queryBuilder = queryBuilder.Where("id = ?", req.ID)
+	case req.TokenHash != "":
+		queryBuilder = queryBuilder.Where("token_hash = ?", req.TokenHash)
+	default:
+		return entity.BusinessInvitation{}, fmt.Errorf("GetSingle - invalid request")
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return entity.BusinessInvitation{}, err
+	}
+
+	return r.scan(r.pg.Pool.QueryRow(ctx, query, args...))
+}
+
+func (r *BusinessInvitationRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessInvitationList, error) {
+	var response = entity.BusinessInvitationList{}
+
+	queryBuilder := r.pg.Builder.
+		Select(`id, business_id, email, role, token_hash, COALESCE(invited_by::text, ''), status, expires_at, created_at, updated_at`).
+		From("business_invitations")
+
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := r.scan(rows)
+		if err != nil {
+			return response, err
+		}
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("business_invitations").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Revoke cancels a pending invitation.
+func (r *BusinessInvitationRepo) Revoke(ctx context.Context, req entity.BusinessInvitation) error {
+	query, args, err := r.pg.Builder.Update("business_invitations").
+		SetMap(map[string]interface{}{
+			"status":     "revoked",
+			"updated_at": time.Now(),
+		}).
+		Where("id = ? AND business_id = ? AND status = 'pending'", req.ID, req.BusinessID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Accept marks a pending invitation accepted and adds userID to the business
+// with the invited role, both or neither. pgx.ErrNoRows means the invitation
+// was accepted or revoked in the meantime.
+func (r *BusinessInvitationRepo) Accept(ctx context.Context, req entity.BusinessInvitation, userID string) (entity.BusinessMember, error) {
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := r.pg.Builder.Update("business_invitations").
+		SetMap(map[string]interface{}{
+			"status":     "accepted",
+			"updated_at": time.Now(),
+		}).
+		Where("id = ? AND status = 'pending'", req.ID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	n, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.BusinessMember{}, pgx.ErrNoRows
+	}
+
+	member := entity.BusinessMember{
+		ID:         uuid.NewString(),
+		BusinessID: req.BusinessID,
+		UserID:     userID,
+		Role:       req.Role,
+	}
+
+	query, args, err = r.pg.Builder.Insert("business_members").
+		Columns(`id, business_id, user_id, role`).
+		Values(member.ID, member.BusinessID, member.UserID, member.Role).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	member.CreatedAt = time.Now().Format(time.RFC3339)
+	member.UpdatedAt = member.CreatedAt
+	return member, nil
+}
+
+func (r *BusinessInvitationRepo) scan(row pgx.Row) (entity.BusinessInvitation, error) {
+	var (
+		item                            entity.BusinessInvitation
+		expiresAt, createdAt, updatedAt time.Time
+	)
+
+	err := row.Scan(&item.ID, &item.BusinessID, &item.Email, &item.Role, &item.TokenHash,
+		&item.InvitedBy, &item.Status, &expiresAt, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.BusinessInvitation{}, err
+	}
+
+	item.ExpiresAt = expiresAt.Format(time.RFC3339)
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	item.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return item, nil
+}
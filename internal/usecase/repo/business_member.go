@@ -0,0 +1,216 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/jackc/pgx/v4"
+)
+
+type BusinessMemberRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewBusinessMemberRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *BusinessMemberRepo {
+	return &BusinessMemberRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// GetSingle finds the membership of req.UserID in req.BusinessID.
+func (r *BusinessMemberRepo) GetSingle(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error) {
+	response := entity.BusinessMember{}
+	var createdAt, updatedAt time.Time
+
+	if req.BusinessID == "" || req.UserID == "" {
+		return entity.BusinessMember{}, fmt.Errorf("GetSingle - invalid request")
+	}
+
+	query, args, err := r.pg.Builder.
+		Select(`m.id, m.business_id, m.user_id, m.role, u.username, u.full_name, u.email, m.created_at, m.updated_at`).
+		From("business_members m").
+		Join("users u ON u.id = m.user_id").
+		Where("m.business_id = ? AND m.user_id = ?", req.BusinessID, req.UserID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).
+		Scan(&response.ID, &response.BusinessID, &response.UserID, &response.Role,
+			&response.Username, &response.FullName, &response.Email, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	response.CreatedAt = createdAt.Format(time.RFC3339)
+	response.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return response, nil
+}
+
+// GetList filters on the columns of business_members m.
+func (r *BusinessMemberRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.BusinessMemberList, error) {
+	var (
+		response             = entity.BusinessMemberList{}
+		createdAt, updatedAt time.Time
+	)
+
+	queryBuilder := r.pg.Builder.
+		Select(`m.id, m.business_id, m.user_id, m.role, u.username, u.full_name, u.email, m.created_at, m.updated_at`).
+		From("business_members m").
+		Join("users u ON u.id = m.user_id")
+
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.BusinessMember
+		err = rows.Scan(&item.ID, &item.BusinessID, &item.UserID, &item.Role,
+			&item.Username, &item.FullName, &item.Email, &createdAt, &updatedAt)
+		if err != nil {
+			return response, err
+		}
+
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+		item.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("business_members m").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// UpdateRole changes the role of a manager or editor, ownership only moves through TransferOwnership.
+func (r *BusinessMemberRepo) UpdateRole(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error) {
+	query, args, err := r.pg.Builder.Update("business_members").
+		SetMap(map[string]interface{}{
+			"role":       req.Role,
+			"updated_at": time.Now(),
+		}).
+		Where("business_id = ? AND user_id = ? AND role <> 'owner'", req.BusinessID, req.UserID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.BusinessMember{}, pgx.ErrNoRows
+	}
+
+	return r.GetSingle(ctx, req)
+}
+
+// Delete removes a manager or editor, the owner can't be removed.
+func (r *BusinessMemberRepo) Delete(ctx context.Context, req entity.BusinessMember) error {
+	query, args, err := r.pg.Builder.Delete("business_members").
+		Where("business_id = ? AND user_id = ? AND role <> 'owner'", req.BusinessID, req.UserID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// TransferOwnership makes req.UserID, who must already be a member, the owner
+// of req.BusinessID. The previous owner stays on as a manager.
+func (r *BusinessMemberRepo) TransferOwnership(ctx context.Context, req entity.BusinessMember) (entity.BusinessMember, error) {
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// demote first, a business can't have two owners even for a moment
+	query, args, err := r.pg.Builder.Update("business_members").
+		SetMap(map[string]interface{}{
+			"role":       "manager",
+			"updated_at": time.Now(),
+		}).
+		Where("business_id = ? AND role = 'owner'", req.BusinessID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	query, args, err = r.pg.Builder.Update("business_members").
+		SetMap(map[string]interface{}{
+			"role":       "owner",
+			"updated_at": time.Now(),
+		}).
+		Where("business_id = ? AND user_id = ?", req.BusinessID, req.UserID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	n, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.BusinessMember{}, pgx.ErrNoRows
+	}
+
+	query, args, err = r.pg.Builder.Update("businesses").
+		SetMap(map[string]interface{}{
+			"owner_id":   req.UserID,
+			"updated_at": time.Now(),
+		}).
+		Where("id = ?", req.BusinessID).ToSql()
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.BusinessMember{}, err
+	}
+
+	return r.GetSingle(ctx, req)
+}
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	rediscache "github.com/golanguzb70/redis-cache"
+	"github.com/google/uuid"
+)
+
+// getCached loads the value stored under key into dest. A miss and a broken
+// cache look the same to the caller, both just fall back to the database.
+func getCached(ctx context.Context, redis rediscache.RedisCache, key string, dest interface{}) bool {
+	js, err := redis.Get(ctx, key)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal([]byte(js), dest) == nil
+}
+
+func setCached(ctx context.Context, redis rediscache.RedisCache, key string, value interface{}, ttl time.Duration) error {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return redis.Set(ctx, key, string(js), int(ttl.Seconds()))
+}
+
+// Sessions and user statuses must stop working the moment they are revoked.
+// Dropping the cached copy isn't enough for that, a read that started before
+// the revoke could put the old copy back right after it. So these entries are
+// stored with the version of their key at the time the database was read, a
+// revoke moves the version on and entries of an older version are ignored.
+
+type versionedEntry struct {
+	Version string          `json:"version"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// cacheVersion returns the current version of key, read it before the
+// database and pass it to setCachedVersioned.
+func cacheVersion(ctx context.Context, redis rediscache.RedisCache, key string) string {
+	version, _ := redis.Get(ctx, key+"-version")
+	return version
+}
+
+// getCachedVersioned is getCached for entries written by setCachedVersioned,
+// an entry of an older version is a miss.
+func getCachedVersioned(ctx context.Context, redis rediscache.RedisCache, key string, dest interface{}) bool {
+	var entry versionedEntry
+	if !getCached(ctx, redis, key, &entry) {
+		return false
+	}
+
+	if entry.Version != cacheVersion(ctx, redis, key) {
+		return false
+	}
+
+	return json.Unmarshal(entry.Value, dest) == nil
+}
+
+func setCachedVersioned(ctx context.Context, redis rediscache.RedisCache, key, version string, value interface{}, ttl time.Duration) error {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return setCached(ctx, redis, key, versionedEntry{Version: version, Value: js}, ttl)
+}
+
+// invalidateVersioned moves the version of key on and drops its entry. The
+// version outlives any entry of the old version that may still be written, ttl
+// is the lifetime of the entries.
+func invalidateVersioned(ctx context.Context, redis rediscache.RedisCache, key string, ttl time.Duration) error {
+	err := redis.Set(ctx, key+"-version", uuid.NewString(), int((2 * ttl).Seconds()))
+	if err != nil {
+		return err
+	}
+
+	return redis.Del(ctx, key)
+}
+
+func sessionCacheKey(id string) string {
+	return "session-" + id
+}
+
+func userStatusCacheKey(id string) string {
+	return "user-status-" + id
+}
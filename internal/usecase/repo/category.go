@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// categorySubtreeCTE defines subtree as the id of the category given as its
+// argument and of every category below it.
+const categorySubtreeCTE = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+)`
+
+// categoryAncestorsCTE defines ancestors as every category_id paired with
+// itself and with every category above it as ancestor_id.
+const categoryAncestorsCTE = `WITH RECURSIVE ancestors AS (
+	SELECT id AS category_id, id AS ancestor_id FROM categories
+	UNION
+	SELECT a.category_id, c.parent_id FROM ancestors a JOIN categories c ON c.id = a.ancestor_id WHERE c.parent_id IS NOT NULL
+)`
+
+type CategoryRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewCategoryRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *CategoryRepo {
+	return &CategoryRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *CategoryRepo) Create(ctx context.Context, req entity.Category) (entity.Category, error) {
+	req.ID = uuid.NewString()
+
+	query, args, err := r.pg.Builder.Insert("categories").
+		Columns(`id, slug, parent_id, names`).
+		Values(req.ID, req.Slug, nullUUID(req.ParentID), req.Names).ToSql()
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Category{ID: req.ID})
+}
+
+// GetSingle looks a category up by its id or by its slug.
+func (r *CategoryRepo) GetSingle(ctx context.Context, req entity.Category) (entity.Category, error) {
+	queryBuilder := r.pg.Builder.
+		Select(`id, slug, COALESCE(parent_id::text, ''), names, created_at, updated_at`).
+		From("categories")
+
+	switch {
+	case req.ID != "":
+		queryBuilder = queryBuilder.Where("id = ?", req.ID)
+	case req.Slug != "":
+		queryBuilder = queryBuilder.Where("slug = ?", req.Slug)
+	default:
+		return entity.Category{}, fmt.Errorf("GetSingle - invalid request")
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	return r.scan(r.pg.Pool.QueryRow(ctx, query, args...))
+}
+
+func (r *CategoryRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.CategoryList, error) {
+	var response = entity.CategoryList{}
+
+	queryBuilder := r.pg.Builder.
+		Select(`id, slug, COALESCE(parent_id::text, ''), names, created_at, updated_at`).
+		From("categories")
+
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := r.scan(rows)
+		if err != nil {
+			return response, err
+		}
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("categories").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// GetSubtreeIDs returns the id of the category and of every category below it.
+func (r *CategoryRepo) GetSubtreeIDs(ctx context.Context, req entity.Id) ([]string, error) {
+	query, args, err := r.pg.Builder.Select("id").Prefix(categorySubtreeCTE, req.ID).From("subtree").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Update replaces the slug, parent and names of a category.
+func (r *CategoryRepo) Update(ctx context.Context, req entity.Category) (entity.Category, error) {
+	query, args, err := r.pg.Builder.Update("categories").
+		SetMap(map[string]interface{}{
+			"slug":       req.Slug,
+			"parent_id":  nullUUID(req.ParentID),
+			"names":      req.Names,
+			"updated_at": time.Now(),
+		}).
+		Where("id = ?", req.ID).ToSql()
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Category{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.Category{}, pgx.ErrNoRows
+	}
+
+	return r.GetSingle(ctx, entity.Category{ID: req.ID})
+}
+
+// Delete fails with a foreign key violation while businesses or other
+// categories still use the category.
+func (r *CategoryRepo) Delete(ctx context.Context, req entity.Id) error {
+	query, args, err := r.pg.Builder.Delete("categories").Where("id = ?", req.ID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *CategoryRepo) scan(row pgx.Row) (entity.Category, error) {
+	var (
+		item                 entity.Category
+		createdAt, updatedAt time.Time
+	)
+
+	err := row.Scan(&item.ID, &item.Slug, &item.ParentID, &item.Names, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	item.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return item, nil
+}
+
+// nullUUID stores an empty id as NULL.
+func nullUUID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
@@ -26,7 +26,9 @@ func PrepareFilter(filters []entity.Filter) squirrel.And {
 			or = append(or, squirrel.ILike{e.Column: "%" + e.Value + "%"})
 		}
 	}
-	where = append(where, or)
+	if len(or) > 0 {
+		where = append(where, or)
+	}
 
 	return where
 }
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+)
+
+type IdentityRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewIdentityRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *IdentityRepo {
+	return &IdentityRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *IdentityRepo) Create(ctx context.Context, req entity.UserIdentity) (entity.UserIdentity, error) {
+	req.ID = uuid.NewString()
+
+	query, args, err := r.pg.Builder.Insert("user_identities").
+		Columns(`id, user_id, issuer, subject, email`).
+		Values(req.ID, req.UserID, req.Issuer, req.Subject, req.Email).ToSql()
+	if err != nil {
+		return entity.UserIdentity{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.UserIdentity{}, err
+	}
+
+	req.CreatedAt = time.Now().Format(time.RFC3339)
+	return req, nil
+}
+
+// GetSingle finds the identity by issuer and subject, the pair a provider guarantees to be stable.
+func (r *IdentityRepo) GetSingle(ctx context.Context, req entity.UserIdentity) (entity.UserIdentity, error) {
+	response := entity.UserIdentity{}
+	var createdAt time.Time
+
+	query, args, err := r.pg.Builder.
+		Select(`id, user_id, issuer, subject, email, created_at`).
+		From("user_identities").
+		Where("issuer = ? AND subject = ?", req.Issuer, req.Subject).ToSql()
+	if err != nil {
+		return entity.UserIdentity{}, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).
+		Scan(&response.ID, &response.UserID, &response.Issuer, &response.Subject, &response.Email, &createdAt)
+	if err != nil {
+		return entity.UserIdentity{}, err
+	}
+
+	response.CreatedAt = createdAt.Format(time.RFC3339)
+	return response, nil
+}
@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+)
+
+type LoginEventRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewLoginEventRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *LoginEventRepo {
+	return &LoginEventRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *LoginEventRepo) Create(ctx context.Context, req entity.LoginEvent) (entity.LoginEvent, error) {
+	req.ID = uuid.NewString()
+	// failed attempts for an unknown email have no user
+	userID := sql.NullString{String: req.UserID, Valid: req.UserID != ""}
+
+	query, args, err := r.pg.Builder.Insert("login_events").
+		Columns(`id, user_id, email, success, failure_reason, ip_address, user_agent, platform`).
+		Values(req.ID, userID, req.Email, req.Success, req.FailureReason, req.IPAddress, req.UserAgent, req.Platform).ToSql()
+	if err != nil {
+		return entity.LoginEvent{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.LoginEvent{}, err
+	}
+
+	req.CreatedAt = time.Now().Format(time.RFC3339)
+	return req, nil
+}
+
+func (r *LoginEventRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.LoginEventList, error) {
+	var (
+		response  = entity.LoginEventList{}
+		createdAt time.Time
+	)
+
+	queryBuilder := r.pg.Builder.
+		Select(`id, user_id, email, success, failure_reason, ip_address, user_agent, platform, created_at`).
+		From("login_events")
+
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item   entity.LoginEvent
+			userID sql.NullString
+		)
+		err = rows.Scan(&item.ID, &userID, &item.Email, &item.Success, &item.FailureReason,
+			&item.IPAddress, &item.UserAgent, &item.Platform, &createdAt)
+		if err != nil {
+			return response, err
+		}
+
+		item.UserID = userID.String
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("login_events").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// IsKnownDevice reports whether the user has logged in successfully from this
+// IP address and user agent before, and whether they have logged in at all.
+func (r *LoginEventRepo) IsKnownDevice(ctx context.Context, req entity.LoginEvent) (known bool, firstLogin bool, err error) {
+	query, args, err := r.pg.Builder.
+		Select(`COALESCE(bool_or(ip_address = ? AND md5(user_agent) = md5(?)), false), COUNT(1) = 0`, req.IPAddress, req.UserAgent).
+		From("login_events").
+		Where("user_id = ? AND success", req.UserID).ToSql()
+	if err != nil {
+		return false, false, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).Scan(&known, &firstLogin)
+	if err != nil {
+		return false, false, err
+	}
+
+	return known, firstLogin, nil
+}
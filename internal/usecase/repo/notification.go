@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -29,10 +30,12 @@ func NewNotificationRepo(pg *postgres.Postgres, config *config.Config, logger *l
 
 func (r *NotificationRepo) Create(ctx context.Context, req entity.Notification) (entity.Notification, error) {
 	req.ID = uuid.NewString()
+	// notifications sent by the system itself have no owner
+	ownerID := sql.NullString{String: req.OwnerId, Valid: req.OwnerId != ""}
 
 	query, args, err := r.pg.Builder.Insert("notifications").
 		Columns(`id,owner_id, user_id,email, message, status`).
-		Values(req.ID, req.OwnerId, req.UserID, req.Email, req.Message, req.Status).ToSql()
+		Values(req.ID, ownerID, req.UserID, req.Email, req.Message, req.Status).ToSql()
 	if err != nil {
 		return entity.Notification{}, err
 	}
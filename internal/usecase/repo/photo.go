@@ -0,0 +1,370 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// photoOwner says where the photos of an owner type live: the photos column
+// pointing at the owner, the owner's table and the column of that table that
+// keeps the url of the cover photo, empty being coverEmpty.
+type photoOwner struct {
+	column, table, coverColumn, coverEmpty string
+}
+
+var photoOwners = map[string]photoOwner{
+	"business": {"business_id", "businesses", "photos", "''"},
+	"review":   {"review_id", "reviews", "photos", "''"},
+	"user":     {"user_id", "users", "avatar_id", "NULL"}, // avatar_id is unique
+}
+
+const photoColumns = `id, CASE WHEN business_id IS NOT NULL THEN 'business' WHEN review_id IS NOT NULL THEN 'review' ELSE 'user' END,
+	COALESCE(business_id, review_id, user_id)::text, object_key, url, caption, COALESCE(uploaded_by::text, ''),
+	position, is_cover, created_at, updated_at`
+
+type PhotoRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewPhotoRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *PhotoRepo {
+	return &PhotoRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Create adds a photo at the end of its owner's gallery. The first photo of a
+// gallery always becomes its cover.
+func (r *PhotoRepo) Create(ctx context.Context, req entity.Photo) (entity.Photo, error) {
+	owner, ok := photoOwners[req.OwnerType]
+	if !ok {
+		return entity.Photo{}, fmt.Errorf("unknown photo owner type %q", req.OwnerType)
+	}
+	req.ID = uuid.NewString()
+
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = r.lockOwner(ctx, tx, owner, req.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	var count int
+	query, args, err := r.pg.Builder.Select("COALESCE(MAX(position) + 1, 0), COUNT(1)").
+		From("photos").
+		Where(owner.column+" = ?", req.OwnerID).ToSql()
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&req.Position, &count)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	if count == 0 {
+		req.IsCover = true
+	} else if req.IsCover {
+		if err = r.unsetCover(ctx, tx, owner, req.OwnerID); err != nil {
+			return entity.Photo{}, err
+		}
+	}
+
+	query, args, err = r.pg.Builder.Insert("photos").
+		Columns(owner.column+`, id, object_key, url, caption, uploaded_by, position, is_cover`).
+		Values(req.OwnerID, req.ID, req.ObjectKey, req.URL, req.Caption, nullUUID(req.UploadedBy), req.Position, req.IsCover).ToSql()
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	if err = r.syncCover(ctx, tx, owner, req.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Photo{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.ID})
+}
+
+func (r *PhotoRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Photo, error) {
+	if req.ID == "" {
+		return entity.Photo{}, fmt.Errorf("GetSingle - invalid request")
+	}
+
+	query, args, err := r.pg.Builder.Select(photoColumns).From("photos").Where("id = ?", req.ID).ToSql()
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	return r.scan(r.pg.Pool.QueryRow(ctx, query, args...))
+}
+
+// GetList filters on the owner columns business_id, review_id and user_id.
+func (r *PhotoRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.PhotoList, error) {
+	var response = entity.PhotoList{}
+
+	queryBuilder := r.pg.Builder.Select(photoColumns).From("photos")
+
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := r.scan(rows)
+		if err != nil {
+			return response, err
+		}
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("photos").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Update saves the caption, position and cover flag of a photo. Making it the
+// cover takes the flag from the previous cover.
+func (r *PhotoRepo) Update(ctx context.Context, req entity.Photo) (entity.Photo, error) {
+	owner, ok := photoOwners[req.OwnerType]
+	if !ok {
+		return entity.Photo{}, fmt.Errorf("unknown photo owner type %q", req.OwnerType)
+	}
+
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = r.lockOwner(ctx, tx, owner, req.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	if req.IsCover {
+		if err = r.unsetCover(ctx, tx, owner, req.OwnerID); err != nil {
+			return entity.Photo{}, err
+		}
+	}
+
+	query, args, err := r.pg.Builder.Update("photos").
+		SetMap(map[string]interface{}{
+			"caption":    req.Caption,
+			"position":   req.Position,
+			"is_cover":   req.IsCover,
+			"updated_at": time.Now(),
+		}).
+		Where("id = ? AND "+owner.column+" = ?", req.ID, req.OwnerID).ToSql()
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	n, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.Photo{}, pgx.ErrNoRows
+	}
+
+	if err = r.syncCover(ctx, tx, owner, req.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Photo{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.ID})
+}
+
+// Delete removes a photo and returns it so its object can be removed from the
+// bucket. When it was the cover the first remaining photo takes over.
+func (r *PhotoRepo) Delete(ctx context.Context, req entity.Id) (entity.Photo, error) {
+	photo, err := r.GetSingle(ctx, req)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+	owner := photoOwners[photo.OwnerType]
+
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = r.lockOwner(ctx, tx, owner, photo.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	query, args, err := r.pg.Builder.Delete("photos").Where("id = ?", req.ID).Suffix("RETURNING is_cover").ToSql()
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&photo.IsCover)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	if photo.IsCover {
+		query, args, err = r.pg.Builder.Update("photos").
+			Set("is_cover", true).
+			Where(`id = (SELECT id FROM photos WHERE `+owner.column+` = ? ORDER BY position, created_at LIMIT 1)`, photo.OwnerID).ToSql()
+		if err != nil {
+			return entity.Photo{}, err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			return entity.Photo{}, err
+		}
+	}
+
+	if err = r.syncCover(ctx, tx, owner, photo.OwnerID); err != nil {
+		return entity.Photo{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Photo{}, err
+	}
+
+	return photo, nil
+}
+
+// GetObjectKeys returns the objects of every photo that goes away when the
+// owner given by req.OwnerType and req.OwnerID is deleted, the photos of its
+// reviews included.
+func (r *PhotoRepo) GetObjectKeys(ctx context.Context, req entity.Photo) ([]string, error) {
+	queryBuilder := r.pg.Builder.Select("object_key").From("photos")
+
+	switch req.OwnerType {
+	case "business":
+		queryBuilder = queryBuilder.Where("business_id = ? OR review_id IN (SELECT id FROM reviews WHERE business_id = ?)", req.OwnerID, req.OwnerID)
+	case "review":
+		queryBuilder = queryBuilder.Where("review_id = ?", req.OwnerID)
+	case "user":
+		queryBuilder = queryBuilder.Where("user_id = ? OR review_id IN (SELECT id FROM reviews WHERE user_id = ?)", req.OwnerID, req.OwnerID)
+	default:
+		return nil, fmt.Errorf("unknown photo owner type %q", req.OwnerType)
+	}
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// lockOwner serializes gallery changes of one owner, pgx.ErrNoRows means the
+// owner doesn't exist.
+func (r *PhotoRepo) lockOwner(ctx context.Context, tx pgx.Tx, owner photoOwner, ownerID string) error {
+	query, args, err := r.pg.Builder.Select("id").From(owner.table).Where("id = ?", ownerID).Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return err
+	}
+
+	var id string
+	return tx.QueryRow(ctx, query, args...).Scan(&id)
+}
+
+func (r *PhotoRepo) unsetCover(ctx context.Context, tx pgx.Tx, owner photoOwner, ownerID string) error {
+	query, args, err := r.pg.Builder.Update("photos").
+		SetMap(map[string]interface{}{
+			"is_cover":   false,
+			"updated_at": time.Now(),
+		}).
+		Where(owner.column+" = ? AND is_cover", ownerID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// syncCover copies the url of the cover photo to the owner's legacy column.
+func (r *PhotoRepo) syncCover(ctx context.Context, tx pgx.Tx, owner photoOwner, ownerID string) error {
+	query, args, err := r.pg.Builder.Update(owner.table).
+		Set(owner.coverColumn, squirrel.Expr(
+			"COALESCE((SELECT url FROM photos WHERE "+owner.column+" = ? AND is_cover), "+owner.coverEmpty+")", ownerID)).
+		Where("id = ?", ownerID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *PhotoRepo) scan(row pgx.Row) (entity.Photo, error) {
+	var (
+		item                 entity.Photo
+		createdAt, updatedAt time.Time
+	)
+
+	err := row.Scan(&item.ID, &item.OwnerType, &item.OwnerID, &item.ObjectKey, &item.URL, &item.Caption,
+		&item.UploadedBy, &item.Position, &item.IsCover, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.Photo{}, err
+	}
+
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	item.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return item, nil
+}
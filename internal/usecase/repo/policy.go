@@ -0,0 +1,209 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/casbin/casbin/model"
+)
+
+// PolicyRepo is the casbin adapter for the casbin_rule table. Casbin's adapter
+// interface has no context, so queries run with context.Background().
+type PolicyRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+
+	mu     sync.Mutex
+	loaded []policyRule // the rules of the last successful load
+}
+
+type policyRule struct {
+	ptype string
+	rule  []string
+}
+
+var policyColumns = []string{"v0", "v1", "v2", "v3", "v4", "v5"}
+
+func NewPolicyRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *PolicyRepo {
+	return &PolicyRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// LoadPolicy loads every rule into the model. Casbin clears the model before
+// calling it, so when the rules can't be read the ones of the last successful
+// load are put back and the error is returned. The enforcer then keeps
+// enforcing the previous policy instead of denying everything.
+func (r *PolicyRepo) LoadPolicy(m model.Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules, err := r.getRules()
+	if err != nil {
+		rules = r.loaded
+	} else {
+		r.loaded = rules
+	}
+
+	for _, p := range rules {
+		sec := p.ptype[:1]
+		if _, ok := m[sec][p.ptype]; !ok {
+			continue
+		}
+		m[sec][p.ptype].Policy = append(m[sec][p.ptype].Policy, p.rule)
+	}
+
+	return err
+}
+
+// getRules reads every stored rule, a rule isn't returned unless all of them
+// could be read.
+func (r *PolicyRepo) getRules() ([]policyRule, error) {
+	query, args, err := r.pg.Builder.
+		Select(`ptype, v0, v1, v2, v3, v4, v5`).
+		From("casbin_rule").
+		OrderBy("id").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []policyRule
+	for rows.Next() {
+		var (
+			ptype string
+			rule  = make([]string, len(policyColumns))
+		)
+
+		err = rows.Scan(&ptype, &rule[0], &rule[1], &rule[2], &rule[3], &rule[4], &rule[5])
+		if err != nil {
+			return nil, err
+		}
+		if ptype == "" {
+			continue
+		}
+
+		// columns the rule doesn't use are stored as ''
+		for len(rule) > 0 && rule[len(rule)-1] == "" {
+			rule = rule[:len(rule)-1]
+		}
+		rules = append(rules, policyRule{ptype: ptype, rule: rule})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// SavePolicy replaces all stored rules with the ones in the model.
+func (r *PolicyRepo) SavePolicy(m model.Model) error {
+	ctx := context.Background()
+
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "DELETE FROM casbin_rule")
+	if err != nil {
+		return err
+	}
+
+	for _, sec := range []string{"p", "g"} {
+		for ptype, assertion := range m[sec] {
+			for _, rule := range assertion.Policy {
+				query, args, err := r.insertRule(ptype, rule)
+				if err != nil {
+					return err
+				}
+
+				_, err = tx.Exec(ctx, query, args...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AddPolicy stores a single rule.
+func (r *PolicyRepo) AddPolicy(sec string, ptype string, rule []string) error {
+	query, args, err := r.insertRule(ptype, rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pg.Pool.Exec(context.Background(), query, args...)
+	return err
+}
+
+// RemovePolicy deletes a single rule.
+func (r *PolicyRepo) RemovePolicy(sec string, ptype string, rule []string) error {
+	where := squirrel.Eq{"ptype": ptype}
+	for i, column := range policyColumns {
+		value := ""
+		if i < len(rule) {
+			value = rule[i]
+		}
+		where[column] = value
+	}
+
+	return r.delete(where)
+}
+
+// RemoveFilteredPolicy deletes the rules whose fields starting at fieldIndex
+// match fieldValues, an empty value matches anything.
+func (r *PolicyRepo) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	where := squirrel.Eq{"ptype": ptype}
+	for i, value := range fieldValues {
+		if value == "" || fieldIndex+i >= len(policyColumns) {
+			continue
+		}
+		where[policyColumns[fieldIndex+i]] = value
+	}
+
+	return r.delete(where)
+}
+
+func (r *PolicyRepo) insertRule(ptype string, rule []string) (string, []interface{}, error) {
+	values := []interface{}{ptype}
+	for i := range policyColumns {
+		value := ""
+		if i < len(rule) {
+			value = strings.TrimSpace(rule[i])
+		}
+		values = append(values, value)
+	}
+
+	return r.pg.Builder.Insert("casbin_rule").
+		Columns(`ptype, v0, v1, v2, v3, v4, v5`).
+		Values(values...).
+		Suffix("ON CONFLICT DO NOTHING").ToSql()
+}
+
+func (r *PolicyRepo) delete(where squirrel.Eq) error {
+	query, args, err := r.pg.Builder.Delete("casbin_rule").Where(where).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pg.Pool.Exec(context.Background(), query, args...)
+	return err
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+)
+
+// reviewReactionTypes are the reactions a review can get, the database keeps
+// a count of each on the review.
+var reviewReactionTypes = map[string]bool{
+	"useful": true,
+	"funny":  true,
+	"cool":   true,
+}
+
+type ReviewReactionRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewReviewReactionRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *ReviewReactionRepo {
+	return &ReviewReactionRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Create adds the reaction, a reaction the user already gave is left as it is.
+func (r *ReviewReactionRepo) Create(ctx context.Context, req entity.ReviewReaction) error {
+	return r.change(ctx, req, r.pg.Builder.Insert("review_reactions").
+		Columns(`review_id, user_id, type`).
+		Values(req.ReviewID, req.UserID, req.Type).
+		Suffix("ON CONFLICT DO NOTHING"))
+}
+
+// Delete removes the reaction, it is no error when the user didn't give it.
+func (r *ReviewReactionRepo) Delete(ctx context.Context, req entity.ReviewReaction) error {
+	return r.change(ctx, req, r.pg.Builder.Delete("review_reactions").
+		Where("review_id = ? AND user_id = ? AND type = ?", req.ReviewID, req.UserID, req.Type))
+}
+
+// GetTypes returns the reaction types the user gave to the given reviews by
+// review id.
+func (r *ReviewReactionRepo) GetTypes(ctx context.Context, userID string, reviewIDs ...string) (map[string][]string, error) {
+	response := make(map[string][]string, len(reviewIDs))
+	if len(reviewIDs) == 0 {
+		return response, nil
+	}
+
+	query, args, err := r.pg.Builder.Select("review_id, type").
+		From("review_reactions").
+		Where("user_id = ?", userID).
+		Where(squirrel.Eq{"review_id": reviewIDs}).
+		OrderBy("type").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reviewID, typ string
+		if err = rows.Scan(&reviewID, &typ); err != nil {
+			return nil, err
+		}
+		response[reviewID] = append(response[reviewID], typ)
+	}
+
+	return response, rows.Err()
+}
+
+// change runs the insert or delete of a reaction, a trigger moves the count
+// on the review.
+func (r *ReviewReactionRepo) change(ctx context.Context, req entity.ReviewReaction, stmt squirrel.Sqlizer) error {
+	if !reviewReactionTypes[req.Type] {
+		return fmt.Errorf("unknown reaction type %q", req.Type)
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// ReviewReplyRepo keeps the replies of businesses to reviews. A review has at
+// most one reply, so replies are looked up by the id of their review.
+type ReviewReplyRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewReviewReplyRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *ReviewReplyRepo {
+	return &ReviewReplyRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Set writes the reply to req.ReviewID, or replaces its message when there is
+// one, the member who writes it becomes its author. created tells the two
+// apart, it is decided by the same statement so concurrent replies can't both
+// create one.
+func (r *ReviewReplyRepo) Set(ctx context.Context, req entity.ReviewReply) (reply entity.ReviewReply, created bool, err error) {
+	query, args, err := r.pg.Builder.Insert("review_replies").
+		Columns(`id, review_id, author_id, message`).
+		Values(uuid.NewString(), req.ReviewID, nullUUID(req.AuthorID), req.Message).
+		Suffix(`ON CONFLICT (review_id) DO UPDATE SET message = EXCLUDED.message, author_id = EXCLUDED.author_id, updated_at = now()
+			RETURNING ` + reviewReplyColumns + `, xmax = 0`).ToSql()
+	if err != nil {
+		return entity.ReviewReply{}, false, err
+	}
+
+	var createdAt, updatedAt time.Time
+	err = r.pg.Pool.QueryRow(ctx, query, args...).
+		Scan(&reply.ID, &reply.ReviewID, &reply.AuthorID, &reply.Message, &createdAt, &updatedAt, &created)
+	if err != nil {
+		return entity.ReviewReply{}, false, err
+	}
+
+	reply.CreatedAt = createdAt.Format(time.RFC3339)
+	reply.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return reply, created, nil
+}
+
+// GetSingle returns the reply to the review with the given id.
+func (r *ReviewReplyRepo) GetSingle(ctx context.Context, req entity.Id) (entity.ReviewReply, error) {
+	query, args, err := r.pg.Builder.
+		Select(reviewReplyColumns).
+		From("review_replies").
+		Where("review_id = ?", req.ID).ToSql()
+	if err != nil {
+		return entity.ReviewReply{}, err
+	}
+
+	return scanReviewReply(r.pg.Pool.QueryRow(ctx, query, args...))
+}
+
+// Delete removes the reply to the review with the given id.
+func (r *ReviewReplyRepo) Delete(ctx context.Context, req entity.Id) error {
+	query, args, err := r.pg.Builder.Delete("review_replies").Where("review_id = ?", req.ID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+const reviewReplyColumns = `id, review_id, COALESCE(author_id::text, ''), message, created_at, updated_at`
+
+// scanReviewReply scans a row selected with reviewReplyColumns.
+func scanReviewReply(row pgx.Row) (entity.ReviewReply, error) {
+	var (
+		item                 entity.ReviewReply
+		createdAt, updatedAt time.Time
+	)
+
+	err := row.Scan(&item.ID, &item.ReviewID, &item.AuthorID, &item.Message, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.ReviewReply{}, err
+	}
+
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	item.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return item, nil
+}
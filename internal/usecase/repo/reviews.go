@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Avazbek-02/udevslab-lesson6/config"
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 )
 
 type ReviewRepo struct {
@@ -28,34 +31,99 @@ func NewReviewRepo(pg *postgres.Postgres, config *config.Config, logger *logger.
 	}
 }
 
+// Create posts a review. A user has one review per business, posting again
+// updates the review the user already has, photos included.
 func (r *ReviewRepo) Create(ctx context.Context, req entity.Review) (entity.Review, error) {
-	req.ID = uuid.NewString()
 	photosJSON, err := json.Marshal(req.Photos)
 	if err != nil {
 		return entity.Review{}, fmt.Errorf("failed to marshal photos: %v", err)
 	}
-	fmt.Println(req)
-	query, args, err := r.pg.Builder.Insert("reviews").
-		Columns(`id, user_id, business_id, rating, feedback, photos`).
-		Values(req.ID, req.UserID, req.BusinessID, req.Rating, req.Feedback, photosJSON).ToSql()
+
+	tx, err := r.pg.Pool.Begin(ctx)
 	if err != nil {
 		return entity.Review{}, err
 	}
+	defer tx.Rollback(ctx)
+
+	// the lock also keeps a second review of the user from being inserted
+	// between looking for the existing one and inserting
+	if err = r.lockBusiness(ctx, tx, req.BusinessID); err != nil {
+		return entity.Review{}, err
+	}
 
-	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	query, args, err := r.pg.Builder.Select("id").From("reviews").
+		Where("user_id = ? AND business_id = ?", req.UserID, req.BusinessID).ToSql()
 	if err != nil {
 		return entity.Review{}, err
 	}
-	
-	return req, nil
+
+	if req.Flags == nil {
+		req.Flags = []string{}
+	}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&req.ID)
+	switch {
+	case err == pgx.ErrNoRows:
+		req.ID = uuid.NewString()
+
+		query, args, err = r.pg.Builder.Insert("reviews").
+			Columns(`id, user_id, business_id, rating, feedback, photos, status, flags`).
+			Values(req.ID, req.UserID, req.BusinessID, req.Rating, req.Feedback, photosJSON, req.Status, req.Flags).ToSql()
+		if err != nil {
+			return entity.Review{}, err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			return entity.Review{}, err
+		}
+	case err != nil:
+		return entity.Review{}, err
+	default:
+		if err = r.update(ctx, tx, req); err != nil {
+			return entity.Review{}, err
+		}
+
+		// update only knows what an edit can change, a re-post may also
+		// bring new photos
+		if req.Photos != "" {
+			query, args, err = r.pg.Builder.Update("reviews").
+				Set("photos", photosJSON).
+				Where("id = ?", req.ID).ToSql()
+			if err != nil {
+				return entity.Review{}, err
+			}
+
+			if _, err = tx.Exec(ctx, query, args...); err != nil {
+				return entity.Review{}, err
+			}
+		}
+	}
+
+	if err = r.addRevision(ctx, tx, req.ID, req.UserID); err != nil {
+		return entity.Review{}, err
+	}
+
+	if err = r.refreshRating(ctx, tx, req.BusinessID); err != nil {
+		return entity.Review{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Review{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.ID})
 }
 
+const reviewColumns = `id, user_id, business_id, rating, feedback, photos, status, flags, moderation_note,
+	useful_count, funny_count, cool_count, created_at, updated_at`
+
 func (r *ReviewRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Review, error) {
 	response := entity.Review{}
-	var createdAt time.Time
+	var createdAt, updatedAt time.Time
 
 	queryBuilder := r.pg.Builder.
-		Select(`id, user_id, business_id, rating, feedback, photos, created_at`).
+		Select(reviewColumns).
 		From("reviews")
 
 	switch {
@@ -72,82 +140,76 @@ func (r *ReviewRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Revie
 	}
 
 	err = r.pg.Pool.QueryRow(ctx, query, args...).
-		Scan(&response.ID, &response.UserID, &response.BusinessID, &response.Rating, &response.Feedback, &response.Photos, &createdAt)
+		Scan(&response.ID, &response.UserID, &response.BusinessID, &response.Rating, &response.Feedback, &response.Photos,
+			&response.Status, &response.Flags, &response.ModerationNote, &response.Reactions.Useful, &response.Reactions.Funny, &response.Reactions.Cool, &createdAt, &updatedAt)
 	if err != nil {
 		return entity.Review{}, err
 	}
 
 	response.CreatedAt = createdAt.Format(time.RFC3339)
+	response.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	replies, err := r.getReplies(ctx, response.ID)
+	if err != nil {
+		return entity.Review{}, err
+	}
+	response.Reply = replies[response.ID]
+
 	return response, nil
 }
 
 func (r *ReviewRepo) GetList(ctx context.Context, req entity.GetListFilter) (entity.ReviewList, error) {
 	var response = entity.ReviewList{}
-	var createdAt time.Time
+	var createdAt, updatedAt time.Time
 
 	queryBuilder := r.pg.Builder.
-		Select(`id, user_id, business_id, rating, feedback, photos, created_at`).
+		Select(reviewColumns).
 		From("reviews")
 
-	// Apply filters
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			if filter.Column == "business_id" {
-				if filter.Type == "eq" && filter.Value != "" {
-					// Add filter for business_id
-					queryBuilder = queryBuilder.Where("business_id = ?", filter.Value)
-				}
-				if filter.Type == "eq" && filter.Value == "" {
-					// If business_id is empty, return all reviews
-					break
-				}
-			}
-		}
-	}
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
 
-	// Prepare the SQL query for fetching the reviews
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
 		return response, err
 	}
 
-	// Execute the query for reviews
 	rows, err := r.pg.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return response, err
 	}
 	defer rows.Close()
 
-	// Scan the review records into response.Items
 	for rows.Next() {
 		var item entity.Review
-		err = rows.Scan(&item.ID, &item.UserID, &item.BusinessID, &item.Rating, &item.Feedback, &item.Photos, &createdAt)
+		err = rows.Scan(&item.ID, &item.UserID, &item.BusinessID, &item.Rating, &item.Feedback, &item.Photos,
+			&item.Status, &item.Flags, &item.ModerationNote, &item.Reactions.Useful, &item.Reactions.Funny, &item.Reactions.Cool, &createdAt, &updatedAt)
 		if err != nil {
 			return response, err
 		}
 
 		item.CreatedAt = createdAt.Format(time.RFC3339)
+		item.UpdatedAt = updatedAt.Format(time.RFC3339)
 		response.Items = append(response.Items, item)
 	}
+	rows.Close()
 
-	// Now, count the reviews based on the same filter (business_id)
-	countQueryBuilder := r.pg.Builder.Select("COUNT(1)").From("reviews")
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			if filter.Column == "business_id" && filter.Type == "eq" && filter.Value != "" {
-				// Add the same filter to the COUNT query
-				countQueryBuilder = countQueryBuilder.Where("business_id = ?", filter.Value)
-			}
-		}
+	ids := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		ids = append(ids, item.ID)
+	}
+	replies, err := r.getReplies(ctx, ids...)
+	if err != nil {
+		return response, err
+	}
+	for i := range response.Items {
+		response.Items[i].Reply = replies[response.Items[i].ID]
 	}
 
-	// Prepare and execute the COUNT query
-	countQuery, args, err := countQueryBuilder.ToSql()
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("reviews").Where(where).ToSql()
 	if err != nil {
 		return response, err
 	}
 
-	// Execute the count query
 	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
 	if err != nil {
 		return response, err
@@ -156,53 +218,333 @@ func (r *ReviewRepo) GetList(ctx context.Context, req entity.GetListFilter) (ent
 	return response, nil
 }
 
+// Update changes the rating and feedback of a review and records the change
+// as a revision made by req.EditedBy.
 func (r *ReviewRepo) Update(ctx context.Context, req entity.Review) (entity.Review, error) {
-	mp := make(map[string]interface{})
+	if req.Rating == 0 && req.Feedback == "" {
+		return entity.Review{}, errors.New("no fields to update")
+	}
 
-	if req.Rating != 0 {
-		mp["rating"] = req.Rating
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Review{}, err
 	}
-	if req.Feedback != "" {
-		mp["feedback"] = req.Feedback
+	defer tx.Rollback(ctx)
+
+	businessID, err := r.lockReviewBusiness(ctx, tx, req.ID)
+	if err != nil {
+		return entity.Review{}, err
 	}
-	if req.Photos != "" {
-		mp["photos"] = req.Photos
+
+	if err = r.update(ctx, tx, req); err != nil {
+		return entity.Review{}, err
 	}
 
-	mp["created_at"] = "now()"
+	if err = r.addRevision(ctx, tx, req.ID, req.EditedBy); err != nil {
+		return entity.Review{}, err
+	}
 
-	if len(mp) == 0 {
-		return entity.Review{}, errors.New("no fields to update")
+	if err = r.refreshRating(ctx, tx, businessID); err != nil {
+		return entity.Review{}, err
 	}
 
-	query, args, err := r.pg.Builder.Update("reviews").SetMap(mp).Where("id = ?", req.ID).ToSql()
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Review{}, err
+	}
+
+	res, err := r.GetSingle(ctx, entity.Id{ID: req.ID})
 	if err != nil {
 		return entity.Review{}, err
 	}
 
-	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	return res, nil
+}
+
+// Moderate publishes or rejects a review and updates the rating of its business.
+func (r *ReviewRepo) Moderate(ctx context.Context, req entity.ReviewModeration) (entity.Review, error) {
+	tx, err := r.pg.Pool.Begin(ctx)
 	if err != nil {
 		return entity.Review{}, err
 	}
+	defer tx.Rollback(ctx)
 
-	res, err := r.GetSingle(ctx, entity.Id{ID: req.ID})
+	businessID, err := r.lockReviewBusiness(ctx, tx, req.ReviewID)
 	if err != nil {
 		return entity.Review{}, err
 	}
 
-	return res, nil
+	query, args, err := r.pg.Builder.Update("reviews").
+		SetMap(map[string]interface{}{
+			"status":          req.Status,
+			"moderated_by":    nullUUID(req.ModeratedBy),
+			"moderated_at":    time.Now(),
+			"moderation_note": req.Note,
+		}).
+		Where("id = ?", req.ReviewID).ToSql()
+	if err != nil {
+		return entity.Review{}, err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.Review{}, err
+	}
+
+	if err = r.refreshRating(ctx, tx, businessID); err != nil {
+		return entity.Review{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Review{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.ReviewID})
+}
+
+// GetRevisions lists the versions of a review, newest first.
+func (r *ReviewRepo) GetRevisions(ctx context.Context, req entity.Id, filter entity.GetListFilter) (entity.ReviewRevisionList, error) {
+	var response = entity.ReviewRevisionList{}
+
+	revisions := squirrel.Select(`*, LAG(rating) OVER w AS prev_rating, LAG(feedback) OVER w AS prev_feedback`).
+		From("review_revisions").
+		Where("review_id = ?", req.ID).
+		Suffix("WINDOW w AS (ORDER BY revision)")
+
+	queryBuilder := r.pg.Builder.
+		Select(`id, review_id, revision, rating, COALESCE(feedback, ''), COALESCE(edited_by::text, ''), created_at,
+			prev_rating, COALESCE(prev_feedback, '')`).
+		FromSelect(revisions, "r")
+
+	queryBuilder, _ = PrepareGetListQuery(queryBuilder, filter)
+
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item         entity.ReviewRevision
+			createdAt    time.Time
+			prevRating   *int
+			prevFeedback string
+		)
+		err = rows.Scan(&item.ID, &item.ReviewID, &item.Revision, &item.Rating, &item.Feedback, &item.EditedBy, &createdAt,
+			&prevRating, &prevFeedback)
+		if err != nil {
+			return response, err
+		}
+
+		item.Changes = []entity.ReviewChange{}
+		if prevRating != nil {
+			if *prevRating != item.Rating {
+				item.Changes = append(item.Changes, entity.ReviewChange{
+					Field: "rating",
+					From:  strconv.Itoa(*prevRating),
+					To:    strconv.Itoa(item.Rating),
+				})
+			}
+			if prevFeedback != item.Feedback {
+				item.Changes = append(item.Changes, entity.ReviewChange{
+					Field: "feedback",
+					From:  prevFeedback,
+					To:    item.Feedback,
+				})
+			}
+		}
+
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+		response.Items = append(response.Items, item)
+	}
+	rows.Close()
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("review_revisions").Where("review_id = ?", req.ID).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
 }
 
 func (r *ReviewRepo) Delete(ctx context.Context, req entity.Id) error {
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	businessID, err := r.lockReviewBusiness(ctx, tx, req.ID)
+	if err != nil {
+		return err
+	}
+
 	query, args, err := r.pg.Builder.Delete("reviews").Where("id = ?", req.ID).ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if err = r.refreshRating(ctx, tx, businessID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockBusiness makes the review changes of one business wait for each other,
+// so refreshRating always sees every review committed before it.
+func (r *ReviewRepo) lockBusiness(ctx context.Context, tx pgx.Tx, businessID string) error {
+	query, args, err := r.pg.Builder.Select("id").From("businesses").Where("id = ?", businessID).Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return err
+	}
+
+	var id string
+	return tx.QueryRow(ctx, query, args...).Scan(&id)
+}
+
+// lockReviewBusiness locks the business of a review and returns its id.
+func (r *ReviewRepo) lockReviewBusiness(ctx context.Context, tx pgx.Tx, reviewID string) (string, error) {
+	query, args, err := r.pg.Builder.Select("business_id::text").From("reviews").Where("id = ?", reviewID).ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	var businessID string
+	if err = tx.QueryRow(ctx, query, args...).Scan(&businessID); err != nil {
+		return "", err
+	}
+
+	return businessID, r.lockBusiness(ctx, tx, businessID)
+}
+
+// getReplies returns the replies to the given reviews by review id.
+func (r *ReviewRepo) getReplies(ctx context.Context, reviewIDs ...string) (map[string]*entity.ReviewReply, error) {
+	response := make(map[string]*entity.ReviewReply, len(reviewIDs))
+	if len(reviewIDs) == 0 {
+		return response, nil
+	}
+
+	query, args, err := r.pg.Builder.
+		Select(reviewReplyColumns).
+		From("review_replies").
+		Where(squirrel.Eq{"review_id": reviewIDs}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanReviewReply(rows)
+		if err != nil {
+			return nil, err
+		}
+		response[item.ReviewID] = &item
+	}
+
+	return response, rows.Err()
+}
+
+// update sets the rating and feedback of a review when they are given, and
+// the status and flags of the new pre-screen. An admin decides again on an
+// edited review, a rejected or pending review stays pending until an admin
+// publishes it, even when the edit removed what the pre-screen flagged.
+func (r *ReviewRepo) update(ctx context.Context, tx pgx.Tx, req entity.Review) error {
+	mp := map[string]interface{}{
+		"updated_at": squirrel.Expr("now()"),
+	}
+
+	if req.Rating != 0 {
+		mp["rating"] = req.Rating
+	}
+	if req.Feedback != "" {
+		mp["feedback"] = req.Feedback
+	}
+	if req.Status != "" {
+		mp["status"] = squirrel.Expr("CASE WHEN status IN ('rejected', 'pending') THEN 'pending' ELSE ? END", req.Status)
+		mp["flags"] = req.Flags
+		mp["moderated_by"] = nil
+		mp["moderated_at"] = nil
+		mp["moderation_note"] = ""
+	}
+
+	query, args, err := r.pg.Builder.Update("reviews").SetMap(mp).Where("id = ?", req.ID).ToSql()
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// addRevision records the current rating and feedback of a review as its
+// next revision, unless they are the same as in the last one.
+func (r *ReviewRepo) addRevision(ctx context.Context, tx pgx.Tx, reviewID, editedBy string) error {
+	current := squirrel.Select().
+		Column(`?::uuid, r.id, COALESCE(l.revision, 0) + 1, r.rating, r.feedback, ?::uuid`, uuid.NewString(), nullUUID(editedBy)).
+		From("reviews r").
+		JoinClause(`LEFT JOIN LATERAL (
+			SELECT revision, rating, feedback FROM review_revisions
+			WHERE review_id = r.id ORDER BY revision DESC LIMIT 1
+		) l ON true`).
+		Where("r.id = ?", reviewID).
+		Where("(l.revision IS NULL OR l.rating <> r.rating OR l.feedback IS DISTINCT FROM r.feedback)")
+
+	query, args, err := r.pg.Builder.Insert("review_revisions").
+		Columns(`id, review_id, revision, rating, feedback, edited_by`).
+		Select(current).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
+
+// ratingAggregatesSQL computes review_count, rating_avg and rating_histogram
+// of the business given as its argument from its published reviews.
+const ratingAggregatesSQL = `(SELECT
+	COUNT(1),
+	COALESCE(ROUND(AVG(rating), 2), 0),
+	ARRAY[
+		COUNT(1) FILTER (WHERE rating = 1),
+		COUNT(1) FILTER (WHERE rating = 2),
+		COUNT(1) FILTER (WHERE rating = 3),
+		COUNT(1) FILTER (WHERE rating = 4),
+		COUNT(1) FILTER (WHERE rating = 5)
+	]::INT[]
+FROM reviews WHERE business_id = ? AND status = 'published')`
+
+// refreshRating recomputes the rating aggregates of a business, the caller
+// must hold the lock of lockBusiness.
+func (r *ReviewRepo) refreshRating(ctx context.Context, tx pgx.Tx, businessID string) error {
+	query, args, err := r.pg.Builder.Update("businesses").
+		Set("(review_count, rating_avg, rating_histogram)", squirrel.Expr(ratingAggregatesSQL, businessID)).
+		Where("id = ?", businessID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+	return err
+}
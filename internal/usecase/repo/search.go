@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	rediscache "github.com/golanguzb70/redis-cache"
+)
+
+// suggestSQL matches business, category and event names. A name starting with
+// the typed text scores 1, any other name scores its trigram similarity, so
+// misspellings still show up below the exact prefixes. Among equal scores the
+// businesses with more reviews and the categories with more businesses win.
+// Its arguments are the LIKE prefix pattern, the typed text, the limit and the
+// language of category names.
+const suggestSQL = `SELECT type, id, name FROM (
+	(SELECT 'business' AS type, id::text AS id, name,
+		CASE WHEN lower(name) LIKE $1 THEN 1 ELSE word_similarity($2, name) END AS score,
+		review_count AS popularity
+	FROM businesses
+	WHERE lower(name) LIKE $1 OR $2 <% name
+	ORDER BY score DESC, popularity DESC
+	LIMIT $3)
+	UNION ALL
+	(SELECT 'category', c.id::text, COALESCE(c.names->>$4::text, c.names->>'en'), m.score,
+		(SELECT COUNT(1) FROM business_categories bc WHERE bc.category_id = c.id)
+	FROM categories c
+	CROSS JOIN LATERAL (
+		SELECT MAX(CASE WHEN lower(n.value) LIKE $1 THEN 1 ELSE word_similarity($2, n.value) END) AS score
+		FROM jsonb_each_text(c.names) n
+	) m
+	WHERE m.score >= 0.6
+	ORDER BY 4 DESC, 5 DESC
+	LIMIT $3)
+	UNION ALL
+	(SELECT 'event', id::text, name,
+		CASE WHEN lower(name) LIKE $1 THEN 1 ELSE word_similarity($2, name) END,
+		0
+	FROM events
+	WHERE lower(name) LIKE $1 OR $2 <% name
+	ORDER BY 4 DESC
+	LIMIT $3)
+) s
+ORDER BY score DESC, popularity DESC, length(name), name
+LIMIT $3`
+
+// SearchRepo answers searches that span several kinds of records.
+type SearchRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+	redis  rediscache.RedisCache
+}
+
+func NewSearchRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger, redis rediscache.RedisCache) *SearchRepo {
+	return &SearchRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+		redis:  redis,
+	}
+}
+
+// Suggest returns the names starting with or resembling req.Query, best match
+// first. Answers are cached for a short while since the same prefixes are
+// typed over and over.
+func (r *SearchRepo) Suggest(ctx context.Context, req entity.SuggestRequest) (entity.SuggestionList, error) {
+	var response = entity.SuggestionList{}
+
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+	key := suggestCacheKey(query, req.Lang, req.Limit)
+	if getCached(ctx, r.redis, key, &response) {
+		return response, nil
+	}
+
+	prefix := likeEscaper.Replace(query) + "%"
+	rows, err := r.pg.Pool.Query(ctx, suggestSQL, prefix, query, req.Limit, req.Lang)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	response.Items = []entity.Suggestion{}
+	for rows.Next() {
+		var item entity.Suggestion
+		if err = rows.Scan(&item.Type, &item.ID, &item.Name); err != nil {
+			return response, err
+		}
+		response.Items = append(response.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return response, err
+	}
+	response.Count = len(response.Items)
+
+	if err = setCached(ctx, r.redis, key, response, config.SearchSuggestCacheExpireTime); err != nil {
+		r.logger.Error(err, "Error caching suggestions")
+	}
+
+	return response, nil
+}
+
+// likeEscaper makes the typed text match itself in a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func suggestCacheKey(query, lang string, limit int) string {
+	return "suggest-" + lang + "-" + strconv.Itoa(limit) + "-" + query
+}
@@ -11,20 +11,25 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
 	"github.com/google/uuid"
+	rediscache "github.com/golanguzb70/redis-cache"
 )
 
+// SessionRepo serves GetSingle from redis, since sessions are read on every
+// authenticated request. Every write below drops the cached copy before returning.
 type SessionRepo struct {
 	pg     *postgres.Postgres
 	config *config.Config
 	logger *logger.Logger
+	redis  rediscache.RedisCache
 }
 
 // New -.
-func NewSessionRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *SessionRepo {
+func NewSessionRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger, redis rediscache.RedisCache) *SessionRepo {
 	return &SessionRepo{
 		pg:     pg,
 		config: config,
 		logger: logger,
+		redis:  redis,
 	}
 }
 
@@ -54,6 +59,11 @@ func (r *SessionRepo) Create(ctx context.Context, req entity.Session) (entity.Se
 
 func (r *SessionRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Session, error) {
 	response := entity.Session{}
+	if getCachedVersioned(ctx, r.redis, sessionCacheKey(req.ID), &response) {
+		return response, nil
+	}
+	version := cacheVersion(ctx, r.redis, sessionCacheKey(req.ID))
+
 	var (
 		createdAt, updatedAt    time.Time
 		expiresAt, lastActiveAt sql.NullTime
@@ -84,6 +94,11 @@ func (r *SessionRepo) GetSingle(ctx context.Context, req entity.Id) (entity.Sess
 		response.LastActiveAt = lastActiveAt.Time.Format(time.RFC3339)
 	}
 
+	err = setCachedVersioned(ctx, r.redis, sessionCacheKey(response.ID), version, response, config.SessionCacheExpireTime)
+	if err != nil {
+		r.logger.Error(err, "Error caching session")
+	}
+
 	return response, nil
 }
 
@@ -91,39 +106,11 @@ func (r *SessionRepo) GetList(ctx context.Context, req entity.GetListFilter) (en
 	var response entity.SessionList
 	var createdAt, updatedAt time.Time
 
-	// Start building the query
 	queryBuilder := r.pg.Builder.
 		Select(`id, user_id, ip_address, user_agent, is_active, expires_at, last_active_at, platform, created_at, updated_at`).
 		From("sessions")
 
-	// Apply filters to the query
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			switch filter.Column {
-			case "user_id":
-				if filter.Type == "eq" && filter.Value != "" {
-					queryBuilder = queryBuilder.Where("user_id = ?", filter.Value)
-				}
-			case "ip_address":
-				if filter.Type == "eq" && filter.Value != "" {
-					queryBuilder = queryBuilder.Where("ip_address = ?", filter.Value)
-				}
-			case "is_active":
-				if filter.Type == "eq" && filter.Value != "" {
-					queryBuilder = queryBuilder.Where("is_active = ?", filter.Value)
-				}
-			}
-		}
-	}
-
-	// Apply pagination (LIMIT and OFFSET)
-	if req.Limit > 0 {
-		queryBuilder = queryBuilder.Limit(uint64(req.Limit))
-	}
-	if req.Page > 0 {
-		offset := (req.Page - 1) * req.Limit
-		queryBuilder = queryBuilder.Offset(uint64(offset))
-	}
+	queryBuilder, where := PrepareGetListQuery(queryBuilder, req)
 
 	// Prepare and execute the SQL query
 	query, args, err := queryBuilder.ToSql()
@@ -159,17 +146,7 @@ func (r *SessionRepo) GetList(ctx context.Context, req entity.GetListFilter) (en
 	}
 
 	// Count query to get the total number of records
-	countQueryBuilder := r.pg.Builder.Select("COUNT(1)").From("sessions")
-	if req.Filters != nil {
-		for _, filter := range req.Filters {
-			switch filter.Column {
-			case "user_id":
-				if filter.Type == "eq" && filter.Value != "" {
-					countQueryBuilder = countQueryBuilder.Where("user_id = ?", filter.Value)
-				}
-			}
-		}
-	}
+	countQueryBuilder := r.pg.Builder.Select("COUNT(1)").From("sessions").Where(where)
 
 	// Prepare and execute the count query
 	countQuery, args, err := countQueryBuilder.ToSql()
@@ -203,6 +180,11 @@ func (r *SessionRepo) Update(ctx context.Context, req entity.Session) (entity.Se
 		return entity.Session{}, err
 	}
 
+	err = r.invalidate(ctx, req.ID)
+	if err != nil {
+		return entity.Session{}, err
+	}
+
 	return req, nil
 }
 
@@ -217,7 +199,7 @@ func (r *SessionRepo) Delete(ctx context.Context, req entity.Id) error {
 		return err
 	}
 
-	return nil
+	return r.invalidate(ctx, req.ID)
 }
 
 func (r *SessionRepo) UpdateField(ctx context.Context, req entity.UpdateFieldRequest) (entity.RowsEffected, error) {
@@ -228,17 +210,51 @@ func (r *SessionRepo) UpdateField(ctx context.Context, req entity.UpdateFieldReq
 		mp[item.Column] = item.Value
 	}
 
-	qeury, args, err := r.pg.Builder.Update("sessions").SetMap(mp).Where(PrepareFilter(req.Filter)).ToSql()
+	// the filter can match any number of sessions, RETURNING tells which cache entries to drop
+	qeury, args, err := r.pg.Builder.Update("sessions").SetMap(mp).Where(PrepareFilter(req.Filter)).
+		Suffix("RETURNING id").ToSql()
 	if err != nil {
 		return response, err
 	}
 
-	n, err := r.pg.Pool.Exec(ctx, qeury, args...)
+	rows, err := r.pg.Pool.Query(ctx, qeury, args...)
 	if err != nil {
 		return response, err
 	}
+	defer rows.Close()
 
-	response.RowsEffected = int(n.RowsAffected())
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return response, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return response, err
+	}
+
+	for _, id := range ids {
+		if err = r.invalidate(ctx, id); err != nil {
+			return response, err
+		}
+	}
+
+	response.RowsEffected = len(ids)
 
 	return response, nil
 }
+
+// invalidate drops the cached copy of a session, also the one a concurrent
+// GetSingle may still write. A revoked session must not stay usable until the
+// cache entry expires, so failures are returned to the caller.
+func (r *SessionRepo) invalidate(ctx context.Context, id string) error {
+	err := invalidateVersioned(ctx, r.redis, sessionCacheKey(id), config.SessionCacheExpireTime)
+	if err != nil {
+		r.logger.Error(err, "Error invalidating cached session")
+		return err
+	}
+
+	return nil
+}
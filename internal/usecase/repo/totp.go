@@ -0,0 +1,168 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Avazbek-02/udevslab-lesson6/config"
+	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
+	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+)
+
+type TotpRepo struct {
+	pg     *postgres.Postgres
+	config *config.Config
+	logger *logger.Logger
+}
+
+func NewTotpRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *TotpRepo {
+	return &TotpRepo{
+		pg:     pg,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Create starts (or restarts) enrolment with a new secret. An enabled
+// enrolment is never overwritten, it has to be disabled first.
+func (r *TotpRepo) Create(ctx context.Context, req entity.UserTotp) (entity.UserTotp, error) {
+	query, args, err := r.pg.Builder.Insert("user_totp").
+		Columns(`user_id, secret`).
+		Values(req.UserID, req.Secret).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, recovery_codes = '{}', last_used_step = 0, updated_at = now() WHERE user_totp.is_enabled = false`).
+		ToSql()
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.UserID})
+}
+
+func (r *TotpRepo) GetSingle(ctx context.Context, req entity.Id) (entity.UserTotp, error) {
+	response := entity.UserTotp{}
+	var (
+		createdAt, updatedAt time.Time
+		enabledAt            sql.NullTime
+	)
+
+	query, args, err := r.pg.Builder.
+		Select(`user_id, secret, is_enabled, recovery_codes, last_used_step, enabled_at, created_at, updated_at`).
+		From("user_totp").
+		Where("user_id = ?", req.ID).ToSql()
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, query, args...).
+		Scan(&response.UserID, &response.Secret, &response.IsEnabled, &response.RecoveryCodes,
+			&response.LastUsedStep, &enabledAt, &createdAt, &updatedAt)
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	if enabledAt.Valid {
+		response.EnabledAt = enabledAt.Time.Format(time.RFC3339)
+	}
+	response.CreatedAt = createdAt.Format(time.RFC3339)
+	response.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	return response, nil
+}
+
+func (r *TotpRepo) Update(ctx context.Context, req entity.UserTotp) (entity.UserTotp, error) {
+	mp := map[string]interface{}{
+		"is_enabled":     req.IsEnabled,
+		"recovery_codes": req.RecoveryCodes,
+		"updated_at":     time.Now(),
+	}
+
+	if req.IsEnabled && req.EnabledAt == "" {
+		mp["enabled_at"] = time.Now()
+	}
+
+	query, args, err := r.pg.Builder.Update("user_totp").SetMap(mp).Where("user_id = ?", req.UserID).ToSql()
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return entity.UserTotp{}, err
+	}
+
+	return r.GetSingle(ctx, entity.Id{ID: req.UserID})
+}
+
+// UseStep records that the code of the given time step has been accepted.
+// The step only moves forward, so RowsEffected is 0 when the code (or an
+// older one) was already used and must be rejected as a replay.
+func (r *TotpRepo) UseStep(ctx context.Context, req entity.UserTotp) (entity.RowsEffected, error) {
+	response := entity.RowsEffected{}
+
+	query, args, err := r.pg.Builder.Update("user_totp").
+		Set("last_used_step", req.LastUsedStep).
+		Where("user_id = ? AND last_used_step < ?", req.UserID, req.LastUsedStep).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+
+	response.RowsEffected = int(n.RowsAffected())
+
+	return response, nil
+}
+
+// UseRecoveryCode removes the recovery code hash in req.RecoveryCodes from an
+// enabled enrolment. RowsEffected is 0 when the code isn't there, also when
+// another request used it first.
+func (r *TotpRepo) UseRecoveryCode(ctx context.Context, req entity.UserTotp) (entity.RowsEffected, error) {
+	response := entity.RowsEffected{}
+
+	if len(req.RecoveryCodes) != 1 {
+		return response, nil
+	}
+	code := req.RecoveryCodes[0]
+
+	query, args, err := r.pg.Builder.Update("user_totp").
+		Set("recovery_codes", squirrel.Expr("array_remove(recovery_codes, ?)", code)).
+		Set("updated_at", time.Now()).
+		Where("user_id = ? AND is_enabled AND ? = ANY(recovery_codes)", req.UserID, code).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	n, err := r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return response, err
+	}
+
+	response.RowsEffected = int(n.RowsAffected())
+
+	return response, nil
+}
+
+func (r *TotpRepo) Delete(ctx context.Context, req entity.Id) error {
+	query, args, err := r.pg.Builder.Delete("user_totp").Where("user_id = ?", req.ID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pg.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -11,30 +11,42 @@ import (
 	"github.com/Avazbek-02/udevslab-lesson6/internal/entity"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/logger"
 	"github.com/Avazbek-02/udevslab-lesson6/pkg/postgres"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	rediscache "github.com/golanguzb70/redis-cache"
 )
 
 type UserRepo struct {
 	pg     *postgres.Postgres
 	config *config.Config
 	logger *logger.Logger
+	redis  rediscache.RedisCache
 }
 
 // New 
-func NewUserRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger) *UserRepo {
+func NewUserRepo(pg *postgres.Postgres, config *config.Config, logger *logger.Logger, redis rediscache.RedisCache) *UserRepo {
 	return &UserRepo{
 		pg:     pg,
 		config: config,
 		logger: logger,
+		redis:  redis,
 	}
 }
 
 func (r *UserRepo) Create(ctx context.Context, req entity.User) (entity.User, error) {
 	req.ID = uuid.NewString()
 	fmt.Println(req.UserType)
+
+	// users from a social login don't tell us their gender
+	var gender interface{} = req.Gender
+	if req.Gender == "" {
+		gender = squirrel.Expr("DEFAULT")
+	}
+
 	qeury, args, err := r.pg.Builder.Insert("users").
 		Columns(`id, full_name, email, bio, username, password_hash, user_type, user_role, status, avatar_id, gender`).
-		Values(req.ID, req.FullName, req.Email, req.Bio, req.Username, req.Password, req.UserType, req.UserRole, req.Status, req.AvatarId, req.Gender).ToSql()
+		Values(req.ID, req.FullName, req.Email, req.Bio, req.Username, req.Password, req.UserType, req.UserRole, req.Status, req.AvatarId, gender).ToSql()
 	if err != nil {
 		return entity.User{}, err
 	}
@@ -161,9 +173,6 @@ func (r *UserRepo) Update(ctx context.Context, req entity.User) (entity.User, er
 	if req.Bio != "" && req.Bio != "string" {
 		mp["bio"] = req.Bio
 	}
-	if req.AvatarId != "" && req.AvatarId != "string" {
-		mp["avatar_id"] = req.AvatarId
-	}
 	if req.Gender != "" && req.Gender != "string" {
 		mp["gender"] = req.Gender
 	}
@@ -194,6 +203,13 @@ func (r *UserRepo) Update(ctx context.Context, req entity.User) (entity.User, er
 	if err != nil {
 		return entity.User{}, err
 	}
+
+	if _, ok := mp["status"]; ok {
+		if err = r.invalidateStatus(ctx, req.ID); err != nil {
+			return entity.User{}, err
+		}
+	}
+
 	res, err := r.GetSingle(ctx, entity.UserSingleRequest{ID: req.ID})
 	if err != nil {
 		return entity.User{}, err
@@ -213,5 +229,150 @@ func (r *UserRepo) Delete(ctx context.Context, req entity.Id) error {
 		return err
 	}
 
+	return r.invalidateStatus(ctx, req.ID)
+}
+
+// GetStatus returns only the status of the user, it is called on every authenticated
+// request and is cached in redis like sessions are.
+func (r *UserRepo) GetStatus(ctx context.Context, req entity.Id) (string, error) {
+	var status string
+	if getCachedVersioned(ctx, r.redis, userStatusCacheKey(req.ID), &status) {
+		return status, nil
+	}
+	version := cacheVersion(ctx, r.redis, userStatusCacheKey(req.ID))
+
+	qeury, args, err := r.pg.Builder.Select("status").From("users").Where("id = ?", req.ID).ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, qeury, args...).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+
+	err = setCachedVersioned(ctx, r.redis, userStatusCacheKey(req.ID), version, status, config.SessionCacheExpireTime)
+	if err != nil {
+		r.logger.Error(err, "Error caching user status")
+	}
+
+	return status, nil
+}
+
+// UpdateStatus changes the user's status and records who did it and why.
+func (r *UserRepo) UpdateStatus(ctx context.Context, req entity.UserStatusChange) (entity.UserStatusChange, error) {
+	req.ID = uuid.NewString()
+	changedBy := sql.NullString{String: req.ChangedBy, Valid: req.ChangedBy != ""}
+
+	tx, err := r.pg.Pool.Begin(ctx)
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qeury, args, err := r.pg.Builder.Update("users").
+		SetMap(map[string]interface{}{
+			"status":     req.Status,
+			"updated_at": time.Now(),
+		}).
+		Where("id = ?", req.UserID).ToSql()
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+
+	n, err := tx.Exec(ctx, qeury, args...)
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+	if n.RowsAffected() == 0 {
+		return entity.UserStatusChange{}, pgx.ErrNoRows
+	}
+
+	qeury, args, err = r.pg.Builder.Insert("user_status_history").
+		Columns(`id, user_id, status, reason, changed_by`).
+		Values(req.ID, req.UserID, req.Status, req.Reason, changedBy).ToSql()
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+
+	_, err = tx.Exec(ctx, qeury, args...)
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+
+	err = r.invalidateStatus(ctx, req.UserID)
+	if err != nil {
+		return entity.UserStatusChange{}, err
+	}
+
+	req.CreatedAt = time.Now().Format(time.RFC3339)
+	return req, nil
+}
+
+func (r *UserRepo) GetStatusHistory(ctx context.Context, req entity.GetListFilter) (entity.UserStatusHistory, error) {
+	var (
+		response  = entity.UserStatusHistory{}
+		createdAt time.Time
+	)
+
+	qeuryBuilder := r.pg.Builder.
+		Select(`id, user_id, status, reason, changed_by, created_at`).
+		From("user_status_history")
+
+	qeuryBuilder, where := PrepareGetListQuery(qeuryBuilder, req)
+
+	qeury, args, err := qeuryBuilder.ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	rows, err := r.pg.Pool.Query(ctx, qeury, args...)
+	if err != nil {
+		return response, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item      entity.UserStatusChange
+			changedBy sql.NullString
+		)
+		err = rows.Scan(&item.ID, &item.UserID, &item.Status, &item.Reason, &changedBy, &createdAt)
+		if err != nil {
+			return response, err
+		}
+
+		item.ChangedBy = changedBy.String
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+
+		response.Items = append(response.Items, item)
+	}
+
+	countQuery, args, err := r.pg.Builder.Select("COUNT(1)").From("user_status_history").Where(where).ToSql()
+	if err != nil {
+		return response, err
+	}
+
+	err = r.pg.Pool.QueryRow(ctx, countQuery, args...).Scan(&response.Count)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// invalidateStatus drops the cached status, a blocked user must be rejected on the next request.
+func (r *UserRepo) invalidateStatus(ctx context.Context, id string) error {
+	err := invalidateVersioned(ctx, r.redis, userStatusCacheKey(id), config.SessionCacheExpireTime)
+	if err != nil {
+		r.logger.Error(err, "Error invalidating cached user status")
+		return err
+	}
+
 	return nil
 }
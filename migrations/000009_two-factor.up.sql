@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id UUID PRIMARY KEY NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    is_enabled BOOL NOT NULL DEFAULT false,
+    recovery_codes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    enabled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_status_history (
+    id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status user_status NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS user_status_history_user_id_idx ON user_status_history (user_id, created_at DESC);
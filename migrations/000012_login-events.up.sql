@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(64) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL,
+    user_agent TEXT NOT NULL,
+    platform VARCHAR(16) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_idx ON login_events (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS login_events_device_idx ON login_events (user_id, ip_address, md5(user_agent)) WHERE success;
@@ -0,0 +1,43 @@
+-- casbin policies, one row per line of the former config/policy.csv
+CREATE TABLE IF NOT EXISTS casbin_rule (
+    id BIGSERIAL PRIMARY KEY,
+    ptype VARCHAR(8) NOT NULL,
+    v0 VARCHAR(255) NOT NULL DEFAULT '',
+    v1 VARCHAR(255) NOT NULL DEFAULT '',
+    v2 VARCHAR(255) NOT NULL DEFAULT '',
+    v3 VARCHAR(255) NOT NULL DEFAULT '',
+    v4 VARCHAR(255) NOT NULL DEFAULT '',
+    v5 VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS casbin_rule_unique_idx ON casbin_rule (ptype, v0, v1, v2, v3, v4, v5);
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES
+    ('p', 'unauthorized', '/swagger/*', 'GET'),
+    ('p', 'unauthorized', '/v1/auth/*', 'GET|POST'),
+    ('p', 'unauthorized', '/.well-known/*', 'GET'),
+    ('p', 'user', '/v1/user/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/user/:id', 'GET'),
+    ('p', 'admin', '/v1/user/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'admin', '/v1/admin/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/session/*', 'GET|DELETE'),
+    ('p', 'admin', '/v1/session/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/business/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/business/:id', 'GET'),
+    ('p', 'admin', '/v1/business/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/review/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/review/:id', 'GET'),
+    ('p', 'admin', '/v1/review/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/notification/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/notification/:id', 'GET'),
+    ('p', 'admin', '/v1/notification/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/report/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/report/:id', 'GET'),
+    ('p', 'admin', '/v1/report/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/event/*', 'GET|POST|PUT|DELETE'),
+    ('p', 'user', '/v1/event/:id', 'GET'),
+    ('p', 'admin', '/v1/event/*', 'GET|POST|PUT|DELETE'),
+    ('g', 'user', 'unauthorized', ''),
+    ('g', 'admin', 'user', '')
+ON CONFLICT DO NOTHING;
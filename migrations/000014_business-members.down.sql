@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS business_invitations;
+DROP TABLE IF EXISTS business_members;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS business_members (
+    id UUID PRIMARY KEY NOT NULL,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'manager', 'editor')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (business_id, user_id)
+);
+
+-- businesses.owner_id stays as a copy of the owner member
+CREATE UNIQUE INDEX IF NOT EXISTS business_members_owner_idx ON business_members (business_id) WHERE role = 'owner';
+CREATE INDEX IF NOT EXISTS business_members_user_id_idx ON business_members (user_id);
+
+INSERT INTO business_members (id, business_id, user_id, role)
+SELECT gen_random_uuid(), id, owner_id, 'owner' FROM businesses
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS business_invitations (
+    id UUID PRIMARY KEY NOT NULL,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('manager', 'editor')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS business_invitations_business_id_idx ON business_invitations (business_id, created_at DESC);
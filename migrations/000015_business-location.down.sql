@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS businesses_location_idx;
+
+ALTER TABLE businesses
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS longitude;
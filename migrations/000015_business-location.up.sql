@@ -0,0 +1,6 @@
+ALTER TABLE businesses
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+
+-- radius searches narrow down to a bounding box first
+CREATE INDEX IF NOT EXISTS businesses_location_idx ON businesses (latitude, longitude) WHERE latitude IS NOT NULL;
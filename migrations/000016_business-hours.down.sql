@@ -0,0 +1,6 @@
+DROP FUNCTION IF EXISTS business_is_open(UUID, TEXT, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS business_day_hours(UUID, DATE);
+DROP TABLE IF EXISTS business_hour_overrides;
+DROP TABLE IF EXISTS business_hours;
+
+ALTER TABLE businesses DROP COLUMN IF EXISTS timezone;
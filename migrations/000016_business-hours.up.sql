@@ -0,0 +1,54 @@
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Tashkent';
+
+-- an interval whose closes_at isn't after opens_at ends on the next day
+CREATE TABLE IF NOT EXISTS business_hours (
+    id UUID PRIMARY KEY NOT NULL,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6), -- 0 is Sunday
+    opens_at TIME NOT NULL,
+    closes_at TIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS business_hours_business_id_idx ON business_hours (business_id, day_of_week);
+
+-- the overrides of a date replace its weekly hours, a row without times closes the whole day
+CREATE TABLE IF NOT EXISTS business_hour_overrides (
+    id UUID PRIMARY KEY NOT NULL,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    opens_at TIME,
+    closes_at TIME,
+    note TEXT NOT NULL DEFAULT '',
+    CHECK ((opens_at IS NULL) = (closes_at IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS business_hour_overrides_business_id_idx ON business_hour_overrides (business_id, date);
+
+-- the same rules as pkg/hours, used by the open_now filter of the business list
+CREATE OR REPLACE FUNCTION business_day_hours(p_business_id UUID, p_day DATE)
+RETURNS TABLE (opens_at TIME, closes_at TIME) AS $$
+    SELECT o.opens_at, o.closes_at
+    FROM business_hour_overrides o
+    WHERE o.business_id = p_business_id AND o.date = p_day AND o.opens_at IS NOT NULL
+    UNION ALL
+    SELECT h.opens_at, h.closes_at
+    FROM business_hours h
+    WHERE h.business_id = p_business_id AND h.day_of_week = EXTRACT(DOW FROM p_day)
+      AND NOT EXISTS (
+          SELECT 1 FROM business_hour_overrides o WHERE o.business_id = p_business_id AND o.date = p_day
+      )
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION business_is_open(p_business_id UUID, p_timezone TEXT, p_at TIMESTAMPTZ)
+RETURNS BOOLEAN AS $$
+    WITH now_local AS (
+        SELECT (p_at AT TIME ZONE p_timezone)::date AS day, (p_at AT TIME ZONE p_timezone)::time AS clock
+    )
+    SELECT EXISTS (
+        SELECT 1 FROM now_local l, business_day_hours(p_business_id, l.day) d
+        WHERE l.clock >= d.opens_at AND (d.closes_at <= d.opens_at OR l.clock < d.closes_at)
+    ) OR EXISTS (
+        SELECT 1 FROM now_local l, business_day_hours(p_business_id, l.day - 1) d
+        WHERE d.closes_at <= d.opens_at AND l.clock < d.closes_at
+    )
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,16 @@
+DELETE FROM casbin_rule WHERE ptype = 'p' AND v0 = 'user' AND v1 = '/v1/category/*';
+
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS category VARCHAR(255);
+
+-- a business keeps the english name of one of its categories
+UPDATE businesses b SET category = (
+    SELECT c.names->>'en'
+    FROM business_categories bc
+    JOIN categories c ON c.id = bc.category_id
+    WHERE bc.business_id = b.id
+    ORDER BY bc.created_at, c.slug
+    LIMIT 1
+);
+
+DROP TABLE IF EXISTS business_categories;
+DROP TABLE IF EXISTS categories;
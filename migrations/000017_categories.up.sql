@@ -0,0 +1,131 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY NOT NULL,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    parent_id UUID REFERENCES categories(id),
+    names JSONB NOT NULL DEFAULT '{}', -- by language, {"en": "Cafes", "ru": "Кафе", "uz": "Kafelar"}
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+-- a category that is still in use can't be deleted
+CREATE TABLE IF NOT EXISTS business_categories (
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (business_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS business_categories_category_id_idx ON business_categories (category_id);
+
+INSERT INTO categories (id, slug, names) VALUES
+    (gen_random_uuid(), 'food-and-drink', '{"en": "Food & Drink", "ru": "Еда и напитки", "uz": "Ovqat va ichimliklar"}'),
+    (gen_random_uuid(), 'shopping', '{"en": "Shopping", "ru": "Магазины", "uz": "Do''konlar"}'),
+    (gen_random_uuid(), 'health-and-beauty', '{"en": "Health & Beauty", "ru": "Здоровье и красота", "uz": "Salomatlik va go''zallik"}'),
+    (gen_random_uuid(), 'services', '{"en": "Services", "ru": "Услуги", "uz": "Xizmatlar"}'),
+    (gen_random_uuid(), 'travel', '{"en": "Travel", "ru": "Путешествия", "uz": "Sayohat"}')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO categories (id, slug, parent_id, names)
+SELECT gen_random_uuid(), c.slug, p.id, c.names::jsonb
+FROM (VALUES
+    ('restaurants', 'food-and-drink', '{"en": "Restaurants", "ru": "Рестораны", "uz": "Restoranlar"}'),
+    ('cafes', 'food-and-drink', '{"en": "Cafes", "ru": "Кафе", "uz": "Kafelar"}'),
+    ('fast-food', 'food-and-drink', '{"en": "Fast Food", "ru": "Фастфуд", "uz": "Fast-fud"}'),
+    ('bakeries', 'food-and-drink', '{"en": "Bakeries", "ru": "Пекарни", "uz": "Novvoyxonalar"}'),
+    ('bars', 'food-and-drink', '{"en": "Bars", "ru": "Бары", "uz": "Barlar"}'),
+    ('clothing', 'shopping', '{"en": "Clothing", "ru": "Одежда", "uz": "Kiyim-kechak"}'),
+    ('groceries', 'shopping', '{"en": "Groceries", "ru": "Продукты", "uz": "Oziq-ovqat"}'),
+    ('electronics', 'shopping', '{"en": "Electronics", "ru": "Электроника", "uz": "Elektronika"}'),
+    ('beauty-salons', 'health-and-beauty', '{"en": "Beauty Salons", "ru": "Салоны красоты", "uz": "Go''zallik salonlari"}'),
+    ('pharmacies', 'health-and-beauty', '{"en": "Pharmacies", "ru": "Аптеки", "uz": "Dorixonalar"}'),
+    ('clinics', 'health-and-beauty', '{"en": "Clinics", "ru": "Клиники", "uz": "Klinikalar"}'),
+    ('fitness', 'health-and-beauty', '{"en": "Fitness", "ru": "Фитнес", "uz": "Fitnes"}'),
+    ('auto-services', 'services', '{"en": "Auto Services", "ru": "Автосервисы", "uz": "Avtoservislar"}'),
+    ('education', 'services', '{"en": "Education", "ru": "Образование", "uz": "Ta''lim"}'),
+    ('hotels', 'travel', '{"en": "Hotels", "ru": "Гостиницы", "uz": "Mehmonxonalar"}')
+) AS c (slug, parent_slug, names)
+JOIN categories p ON p.slug = c.parent_slug
+ON CONFLICT DO NOTHING;
+
+-- map the free-text categories businesses had onto the taxonomy, spellings
+-- are compared lower-cased with punctuation turned into single spaces
+CREATE TEMPORARY TABLE category_aliases (alias TEXT PRIMARY KEY, slug TEXT NOT NULL);
+
+INSERT INTO category_aliases (alias, slug)
+SELECT replace(slug, '-', ' '), slug FROM categories
+UNION
+SELECT lower(names->>'en'), slug FROM categories WHERE names ? 'en'
+UNION
+SELECT lower(names->>'ru'), slug FROM categories WHERE names ? 'ru'
+UNION
+SELECT lower(names->>'uz'), slug FROM categories WHERE names ? 'uz'
+UNION
+SELECT alias, slug FROM (VALUES
+    ('restaurant', 'restaurants'), ('restoran', 'restaurants'), ('ресторан', 'restaurants'),
+    ('cafe', 'cafes'), ('kafe', 'cafes'), ('coffee', 'cafes'), ('coffee shop', 'cafes'),
+    ('coffeeshop', 'cafes'), ('coffee house', 'cafes'), ('кофейня', 'cafes'),
+    ('fastfood', 'fast-food'), ('fast food', 'fast-food'), ('burgers', 'fast-food'),
+    ('bakery', 'bakeries'), ('novvoyxona', 'bakeries'), ('пекарня', 'bakeries'),
+    ('bar', 'bars'), ('pub', 'bars'),
+    ('shop', 'shopping'), ('store', 'shopping'), ('magazin', 'shopping'), ('магазин', 'shopping'),
+    ('clothes', 'clothing'), ('fashion', 'clothing'),
+    ('grocery', 'groceries'), ('supermarket', 'groceries'), ('market', 'groceries'), ('bozor', 'groceries'),
+    ('beauty salon', 'beauty-salons'), ('salon', 'beauty-salons'), ('barbershop', 'beauty-salons'),
+    ('pharmacy', 'pharmacies'), ('dorixona', 'pharmacies'), ('apteka', 'pharmacies'), ('аптека', 'pharmacies'),
+    ('clinic', 'clinics'), ('hospital', 'clinics'), ('klinika', 'clinics'), ('клиника', 'clinics'),
+    ('gym', 'fitness'), ('sport', 'fitness'),
+    ('car service', 'auto-services'), ('auto service', 'auto-services'), ('car wash', 'auto-services'),
+    ('school', 'education'), ('courses', 'education'), ('training center', 'education'),
+    ('hotel', 'hotels'), ('hostel', 'hotels'), ('mehmonxona', 'hotels'), ('гостиница', 'hotels')
+) AS a (alias, slug)
+ON CONFLICT DO NOTHING;
+
+-- anything else becomes a top level category of its own for an admin to sort
+-- out. The old categories were up to 255 characters and slugs are 64, so long
+-- ones are cut, and a slug that is already taken, by an existing category or
+-- one added here, gets the first free number. Businesses find their new
+-- category by the full text, not by the cut slug
+DO $$
+DECLARE
+    c RECORD;
+    candidate TEXT;
+    n INT;
+BEGIN
+    FOR c IN
+        SELECT g.alias, g.name, rtrim(left(replace(g.alias, ' ', '-'), 64), '-') AS base
+        FROM (
+            SELECT lower(btrim(regexp_replace(category, '[^[:alnum:]]+', ' ', 'g'))) AS alias, min(btrim(category)) AS name
+            FROM businesses
+            GROUP BY 1
+        ) g
+        WHERE g.alias <> '' AND NOT EXISTS (SELECT 1 FROM category_aliases a WHERE a.alias = g.alias)
+        ORDER BY g.alias
+    LOOP
+        candidate := c.base;
+        n := 1;
+        WHILE EXISTS (SELECT 1 FROM categories WHERE slug = candidate) LOOP
+            n := n + 1;
+            candidate := rtrim(left(c.base, 63 - length(n::text)), '-') || '-' || n;
+        END LOOP;
+
+        INSERT INTO categories (id, slug, names) VALUES (gen_random_uuid(), candidate, jsonb_build_object('en', c.name));
+        INSERT INTO category_aliases (alias, slug) VALUES (c.alias, candidate);
+    END LOOP;
+END $$;
+
+INSERT INTO business_categories (business_id, category_id)
+SELECT b.id, c.id
+FROM businesses b
+JOIN category_aliases a ON a.alias = lower(btrim(regexp_replace(b.category, '[^[:alnum:]]+', ' ', 'g')))
+JOIN categories c ON c.slug = a.slug
+ON CONFLICT DO NOTHING;
+
+DROP TABLE category_aliases;
+
+ALTER TABLE businesses DROP COLUMN IF EXISTS category;
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES
+    ('p', 'user', '/v1/category/*', 'GET')
+ON CONFLICT DO NOTHING;
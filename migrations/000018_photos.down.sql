@@ -0,0 +1,3 @@
+DELETE FROM casbin_rule WHERE ptype = 'p' AND v0 IN ('user', 'admin') AND v1 = '/v1/photo/*';
+
+DROP TABLE IF EXISTS photos;
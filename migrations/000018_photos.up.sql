@@ -0,0 +1,47 @@
+-- a photo belongs to exactly one business, review or user. The photos columns
+-- of businesses and reviews and users.avatar_id keep the url of the cover photo.
+CREATE TABLE IF NOT EXISTS photos (
+    id UUID PRIMARY KEY NOT NULL,
+    business_id UUID REFERENCES businesses(id) ON DELETE CASCADE,
+    review_id UUID REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    object_key VARCHAR(512) NOT NULL,
+    url TEXT NOT NULL,
+    caption TEXT NOT NULL DEFAULT '',
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    position INT NOT NULL DEFAULT 0,
+    is_cover BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(business_id, review_id, user_id) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS photos_business_id_idx ON photos (business_id, position) WHERE business_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS photos_review_id_idx ON photos (review_id, position) WHERE review_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS photos_user_id_idx ON photos (user_id, position) WHERE user_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS photos_business_cover_idx ON photos (business_id) WHERE is_cover AND business_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS photos_review_cover_idx ON photos (review_id) WHERE is_cover AND review_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS photos_user_cover_idx ON photos (user_id) WHERE is_cover AND user_id IS NOT NULL;
+
+-- the single photo every business, review and user had becomes its cover,
+-- the object key is the url without the host and bucket
+INSERT INTO photos (id, business_id, object_key, url, uploaded_by, is_cover)
+SELECT gen_random_uuid(), id, regexp_replace(photos, '^https?://[^/]+/[^/]+/', ''), photos, owner_id, true
+FROM businesses
+WHERE photos ~ '^https?://';
+
+INSERT INTO photos (id, review_id, object_key, url, uploaded_by, is_cover)
+SELECT gen_random_uuid(), id, regexp_replace(btrim(photos, '"'), '^https?://[^/]+/[^/]+/', ''), btrim(photos, '"'), user_id, true
+FROM reviews
+WHERE btrim(photos, '"') ~ '^https?://';
+
+INSERT INTO photos (id, user_id, object_key, url, uploaded_by, is_cover)
+SELECT gen_random_uuid(), id, regexp_replace(avatar_id, '^https?://[^/]+/[^/]+/', ''), avatar_id, id, true
+FROM users
+WHERE avatar_id ~ '^https?://';
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES
+    ('p', 'user', '/v1/photo/*', 'GET|PUT|DELETE'),
+    ('p', 'admin', '/v1/photo/*', 'GET|PUT|DELETE')
+ON CONFLICT DO NOTHING;
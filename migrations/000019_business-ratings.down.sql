@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS reviews_business_id_idx;
+
+ALTER TABLE businesses
+    DROP COLUMN IF EXISTS review_count,
+    DROP COLUMN IF EXISTS rating_avg,
+    DROP COLUMN IF EXISTS rating_histogram;
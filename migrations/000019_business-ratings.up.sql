@@ -0,0 +1,27 @@
+-- kept up to date by the review repo in the same transaction as the review
+ALTER TABLE businesses
+    ADD COLUMN IF NOT EXISTS review_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_avg NUMERIC(3, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_histogram INT[] NOT NULL DEFAULT '{0,0,0,0,0}'; -- reviews with 1 to 5 stars
+
+CREATE INDEX IF NOT EXISTS reviews_business_id_idx ON reviews (business_id);
+
+UPDATE businesses b SET
+    review_count = r.review_count,
+    rating_avg = r.rating_avg,
+    rating_histogram = r.rating_histogram
+FROM (
+    SELECT business_id,
+        COUNT(1) AS review_count,
+        ROUND(AVG(rating), 2) AS rating_avg,
+        ARRAY[
+            COUNT(1) FILTER (WHERE rating = 1),
+            COUNT(1) FILTER (WHERE rating = 2),
+            COUNT(1) FILTER (WHERE rating = 3),
+            COUNT(1) FILTER (WHERE rating = 4),
+            COUNT(1) FILTER (WHERE rating = 5)
+        ]::INT[] AS rating_histogram
+    FROM reviews
+    GROUP BY business_id
+) r
+WHERE r.business_id = b.id;
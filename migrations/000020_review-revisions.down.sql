@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS reviews_user_business_idx;
+DROP TABLE IF EXISTS review_revisions;
+ALTER TABLE reviews DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,50 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL;
+UPDATE reviews SET updated_at = created_at;
+
+-- every version of a review, the first one is the review as it was posted
+CREATE TABLE IF NOT EXISTS review_revisions (
+    id UUID PRIMARY KEY NOT NULL,
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    rating INT NOT NULL,
+    feedback TEXT,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (review_id, revision)
+);
+
+-- a user kept only the newest of several reviews of the same business, the
+-- older ones become its earlier revisions and their photos move over to it
+CREATE TEMP TABLE review_keep AS
+SELECT id,
+    FIRST_VALUE(id) OVER (PARTITION BY user_id, business_id ORDER BY created_at DESC, id DESC) AS keep_id,
+    ROW_NUMBER() OVER (PARTITION BY user_id, business_id ORDER BY created_at, id) AS revision
+FROM reviews;
+
+INSERT INTO review_revisions (id, review_id, revision, rating, feedback, edited_by, created_at)
+SELECT gen_random_uuid(), k.keep_id, k.revision, r.rating, r.feedback, r.user_id, r.created_at
+FROM reviews r
+JOIN review_keep k ON k.id = r.id;
+
+UPDATE photos p SET review_id = k.keep_id, is_cover = false
+FROM review_keep k
+WHERE p.review_id = k.id AND k.id <> k.keep_id;
+
+DELETE FROM reviews r USING review_keep k WHERE r.id = k.id AND k.id <> k.keep_id;
+
+DROP TABLE review_keep;
+
+UPDATE businesses b SET (review_count, rating_avg, rating_histogram) = (
+    SELECT COUNT(1),
+        COALESCE(ROUND(AVG(rating), 2), 0),
+        ARRAY[
+            COUNT(1) FILTER (WHERE rating = 1),
+            COUNT(1) FILTER (WHERE rating = 2),
+            COUNT(1) FILTER (WHERE rating = 3),
+            COUNT(1) FILTER (WHERE rating = 4),
+            COUNT(1) FILTER (WHERE rating = 5)
+        ]::INT[]
+    FROM reviews WHERE business_id = b.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reviews_user_business_idx ON reviews (user_id, business_id);
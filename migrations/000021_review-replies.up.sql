@@ -0,0 +1,9 @@
+-- the public reply of the reviewed business, a review has at most one
+CREATE TABLE IF NOT EXISTS review_replies (
+    id UUID PRIMARY KEY NOT NULL,
+    review_id UUID NOT NULL UNIQUE REFERENCES reviews(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS review_reactions_count ON review_reactions;
+DROP FUNCTION IF EXISTS review_reactions_count_trigger();
+
+DROP INDEX IF EXISTS reviews_business_id_useful_idx;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS useful_count,
+    DROP COLUMN IF EXISTS funny_count,
+    DROP COLUMN IF EXISTS cool_count;
+
+DROP TABLE IF EXISTS review_reactions;
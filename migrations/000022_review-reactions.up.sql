@@ -0,0 +1,46 @@
+-- a user gives a review each type of reaction at most once
+CREATE TABLE IF NOT EXISTS review_reactions (
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('useful', 'funny', 'cool')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (review_id, user_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS review_reactions_user_id_idx ON review_reactions (user_id);
+
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS useful_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS funny_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cool_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS reviews_business_id_useful_idx ON reviews (business_id, useful_count DESC, created_at DESC);
+
+-- the counts on reviews follow every insert and delete of a reaction, also the
+-- ones cascading from a deleted user
+CREATE OR REPLACE FUNCTION review_reactions_count_trigger() RETURNS trigger AS $$
+DECLARE
+    reaction review_reactions;
+    delta INT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        reaction := NEW;
+        delta := 1;
+    ELSE
+        reaction := OLD;
+        delta := -1;
+    END IF;
+
+    UPDATE reviews SET
+        useful_count = useful_count + CASE WHEN reaction.type = 'useful' THEN delta ELSE 0 END,
+        funny_count = funny_count + CASE WHEN reaction.type = 'funny' THEN delta ELSE 0 END,
+        cool_count = cool_count + CASE WHEN reaction.type = 'cool' THEN delta ELSE 0 END
+    WHERE id = reaction.review_id;
+
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS review_reactions_count ON review_reactions;
+CREATE TRIGGER review_reactions_count AFTER INSERT OR DELETE ON review_reactions
+    FOR EACH ROW EXECUTE FUNCTION review_reactions_count_trigger();
@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS reviews_pending_idx;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS flags,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderation_note;
+
+-- every review counts again
+UPDATE businesses b SET (review_count, rating_avg, rating_histogram) = (
+    SELECT COUNT(1),
+        COALESCE(ROUND(AVG(rating), 2), 0),
+        ARRAY[
+            COUNT(1) FILTER (WHERE rating = 1),
+            COUNT(1) FILTER (WHERE rating = 2),
+            COUNT(1) FILTER (WHERE rating = 3),
+            COUNT(1) FILTER (WHERE rating = 4),
+            COUNT(1) FILTER (WHERE rating = 5)
+        ]::INT[]
+    FROM reviews WHERE business_id = b.id
+);
@@ -0,0 +1,10 @@
+-- reviews flagged by the pre-screen wait as pending until an admin approves
+-- or rejects them, only published reviews count towards business ratings
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published', 'rejected')),
+    ADD COLUMN IF NOT EXISTS flags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS moderated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS moderation_note TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS reviews_pending_idx ON reviews (created_at) WHERE status = 'pending';
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS categories_search ON categories;
+DROP TRIGGER IF EXISTS business_categories_search ON business_categories;
+DROP TRIGGER IF EXISTS reviews_search ON reviews;
+DROP TRIGGER IF EXISTS businesses_search ON businesses;
+
+DROP FUNCTION IF EXISTS categories_search_trigger();
+DROP FUNCTION IF EXISTS business_child_search_trigger();
+DROP FUNCTION IF EXISTS refresh_business_search(UUID);
+DROP FUNCTION IF EXISTS businesses_search_trigger();
+
+DROP INDEX IF EXISTS businesses_name_trgm_idx;
+DROP INDEX IF EXISTS businesses_search_vector_idx;
+ALTER TABLE businesses DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS business_search_vector(UUID, TEXT, TEXT);
@@ -0,0 +1,80 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the text a business is found by: its name, the names of its categories in
+-- every language, its description and its newest published reviews. The
+-- 'simple' configuration doesn't stem, the texts are in several languages.
+CREATE OR REPLACE FUNCTION business_search_vector(b_id UUID, b_name TEXT, b_description TEXT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(b_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(n.name, ' ')
+            FROM business_categories bc
+            JOIN categories c ON c.id = bc.category_id
+            CROSS JOIN LATERAL jsonb_each_text(c.names) AS n(lang, name)
+            WHERE bc.business_id = b_id
+        ), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(b_description, '')), 'C') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(r.feedback, ' ')
+            FROM (
+                SELECT feedback FROM reviews
+                WHERE business_id = b_id AND status = 'published'
+                ORDER BY created_at DESC
+                LIMIT 200
+            ) r
+        ), '')), 'D')
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS search_vector tsvector NOT NULL DEFAULT ''::tsvector;
+
+CREATE OR REPLACE FUNCTION businesses_search_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := business_search_vector(NEW.id, NEW.name, NEW.description);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION refresh_business_search(b_id UUID) RETURNS void AS $$
+    UPDATE businesses SET search_vector = business_search_vector(id, name, description) WHERE id = b_id;
+$$ LANGUAGE sql;
+
+-- reviews and business_categories both have a business_id
+CREATE OR REPLACE FUNCTION business_child_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_business_search(OLD.business_id);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM refresh_business_search(NEW.business_id);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION categories_search_trigger() RETURNS trigger AS $$
+BEGIN
+    PERFORM refresh_business_search(business_id) FROM business_categories WHERE category_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS businesses_search ON businesses;
+CREATE TRIGGER businesses_search BEFORE INSERT OR UPDATE OF name, description ON businesses
+    FOR EACH ROW EXECUTE FUNCTION businesses_search_trigger();
+
+DROP TRIGGER IF EXISTS reviews_search ON reviews;
+CREATE TRIGGER reviews_search AFTER INSERT OR DELETE OR UPDATE OF feedback, status ON reviews
+    FOR EACH ROW EXECUTE FUNCTION business_child_search_trigger();
+
+DROP TRIGGER IF EXISTS business_categories_search ON business_categories;
+CREATE TRIGGER business_categories_search AFTER INSERT OR DELETE ON business_categories
+    FOR EACH ROW EXECUTE FUNCTION business_child_search_trigger();
+
+DROP TRIGGER IF EXISTS categories_search ON categories;
+CREATE TRIGGER categories_search AFTER UPDATE OF names ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_search_trigger();
+
+UPDATE businesses SET search_vector = business_search_vector(id, name, description);
+
+CREATE INDEX IF NOT EXISTS businesses_search_vector_idx ON businesses USING GIN (search_vector);
+-- misspelled names are found by trigram similarity
+CREATE INDEX IF NOT EXISTS businesses_name_trgm_idx ON businesses USING GIN (name gin_trgm_ops);
@@ -0,0 +1,5 @@
+DELETE FROM casbin_rule WHERE ptype = 'p' AND v0 = 'unauthorized' AND v1 = '/v1/search/*';
+
+DROP INDEX IF EXISTS events_name_trgm_idx;
+DROP INDEX IF EXISTS events_name_prefix_idx;
+DROP INDEX IF EXISTS businesses_name_prefix_idx;
@@ -0,0 +1,9 @@
+-- type-ahead matches names by prefix first and by trigram similarity for
+-- misspellings. Categories are few and aren't indexed.
+CREATE INDEX IF NOT EXISTS businesses_name_prefix_idx ON businesses (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS events_name_prefix_idx ON events (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS events_name_trgm_idx ON events USING GIN (name gin_trgm_ops);
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES
+    ('p', 'unauthorized', '/v1/search/*', 'GET')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS businesses_rating_avg_idx;
+DROP INDEX IF EXISTS businesses_price_level_idx;
+
+ALTER TABLE businesses DROP COLUMN IF EXISTS price_level;
@@ -0,0 +1,5 @@
+-- 1 is cheap and 4 expensive, null until the business sets it
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS price_level SMALLINT CHECK (price_level BETWEEN 1 AND 4);
+
+CREATE INDEX IF NOT EXISTS businesses_price_level_idx ON businesses (price_level);
+CREATE INDEX IF NOT EXISTS businesses_rating_avg_idx ON businesses (rating_avg);
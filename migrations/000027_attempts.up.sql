@@ -0,0 +1,9 @@
+-- counters of failed otp, second factor and password attempts, a counter
+-- starts over once it has expired
+CREATE TABLE IF NOT EXISTS attempts (
+    key VARCHAR(255) PRIMARY KEY,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attempts_expires_at_idx ON attempts (expires_at);
@@ -101,3 +101,14 @@ func (m *MinIO) Upload(fileName, filePath string) (string, error) {
 
 	return minioURL, nil
 }
+
+// Delete removes an object uploaded with Upload, fileName is the name it was uploaded as.
+func (m *MinIO) Delete(fileName string) error {
+	err := m.client.RemoveObject(context.Background(), m.Cf.MinIOBucketName, fileName, minio.RemoveObjectOptions{})
+	if err != nil {
+		slog.Error("Error while deleting file", "fileName", fileName, "bucket", m.Cf.MinIOBucketName, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -61,6 +61,65 @@ func GenerateOtpEmailBody(otp string) (string, error) {
 	return builder.String(), nil
 }
 
+// GeneratePasswordResetEmailBody generates the HTML email body with a password reset code
+func GeneratePasswordResetEmailBody(otp string) (string, error) {
+	templateString := `
+<!DOCTYPE html>
+<html>
+<body>
+    <p>Your code to reset your YELP account password {{.Code}},</p>
+    <p>If you did not request a password reset, you can ignore this email.</p>
+</body>
+</html>
+`
+	tmpl, err := template.New("email").Parse(templateString)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email template: %w", err)
+	}
+	otpData := Otp{otp}
+
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, otpData)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute email template: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
+type BusinessInvitation struct {
+	Business string
+	Role     string
+	Token    string
+}
+
+// GenerateBusinessInvitationEmailBody generates the HTML email body inviting someone to manage a business
+func GenerateBusinessInvitationEmailBody(business, role, token string) (string, error) {
+	templateString := `
+<!DOCTYPE html>
+<html>
+<body>
+    <p>You were invited to join {{html .Business}} on YELP as {{.Role}}.</p>
+    <p>Log in with this email address and accept the invitation with the code {{.Token}}</p>
+    <p>If you don't want to join, you can ignore this email.</p>
+</body>
+</html>
+`
+	tmpl, err := template.New("email").Parse(templateString)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email template: %w", err)
+	}
+	invitationData := BusinessInvitation{business, role, token}
+
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, invitationData)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute email template: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
 // sendEmail sends an email using SMTP
 func SendEmail(smtpHost, smtpPort, from, password, to, body string) error {
 	auth := smtp.PlainAuth("", from, password, smtpHost)
@@ -0,0 +1,15 @@
+package etc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateToken returns a hex encoded, cryptographically random token of n bytes.
+func GenerateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,137 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Place is a named point from the gazetteer. Level is "district" or "city",
+// a district is preferred over the city it lies in.
+type Place struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Level     string
+}
+
+// Gazetteer finds places mentioned in free-text addresses.
+type Gazetteer struct {
+	places []Place
+	names  []string // normalized Name of places[i]
+}
+
+var levels = map[string]int{
+	"city":     1,
+	"district": 2,
+}
+
+// LoadGazetteer reads a csv file with the header name,latitude,longitude,level.
+// The same place may appear under several spellings.
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gazetteer{}
+	for i, record := range records {
+		if i == 0 && record[0] == "name" {
+			continue
+		}
+
+		place := Place{
+			Name:  strings.TrimSpace(record[0]),
+			Level: strings.TrimSpace(record[3]),
+		}
+		if place.Latitude, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 64); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+		}
+		if place.Longitude, err = strconv.ParseFloat(strings.TrimSpace(record[2]), 64); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, i+1, err)
+		}
+		if !ValidPoint(place.Latitude, place.Longitude) {
+			return nil, fmt.Errorf("%s line %d: coordinates out of range", path, i+1)
+		}
+		if strings.TrimSpace(normalize(place.Name)) == "" {
+			return nil, fmt.Errorf("%s line %d: name is empty", path, i+1)
+		}
+		if _, ok := levels[place.Level]; !ok {
+			return nil, fmt.Errorf("%s line %d: unknown level %q", path, i+1, place.Level)
+		}
+
+		g.places = append(g.places, place)
+		g.names = append(g.names, normalize(place.Name))
+	}
+
+	return g, nil
+}
+
+// Geocode returns the most specific place named in address. Between places of
+// the same level the one mentioned last wins, addresses usually end with the
+// city, so "Samarkand Darvoza, Tashkent" is in Tashkent.
+func (g *Gazetteer) Geocode(address string) (Place, bool) {
+	if g == nil {
+		return Place{}, false
+	}
+
+	text := normalize(address)
+	best, bestEnd := -1, 0
+	for i, name := range g.names {
+		at := strings.LastIndex(text, name)
+		if at == -1 {
+			continue
+		}
+		end := at + len(name)
+
+		if best == -1 ||
+			levels[g.places[i].Level] > levels[g.places[best].Level] ||
+			levels[g.places[i].Level] == levels[g.places[best].Level] && end > bestEnd {
+			best, bestEnd = i, end
+		}
+	}
+
+	if best == -1 {
+		return Place{}, false
+	}
+	return g.places[best], true
+}
+
+// normalize lower-cases s, drops apostrophes so "Farg'ona" matches "Fargona"
+// and pads every word with spaces so names only match whole words.
+func normalize(s string) string {
+	var b strings.Builder
+	b.WriteByte(' ')
+
+	space := true
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '\'' || r == '`' || r == 'ʻ' || r == 'ʼ' || r == '’' || r == '‘':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+
+	if !space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
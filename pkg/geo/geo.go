@@ -0,0 +1,43 @@
+// Package geo has the distance math behind "near me" searches and a small
+// gazetteer that turns addresses into coordinates without an external service.
+package geo
+
+import (
+	"math"
+)
+
+// EarthRadiusM is the mean radius of the earth in meters.
+const EarthRadiusM = 6371000.0
+
+// ValidPoint reports whether lat and lng are a point on the earth.
+func ValidPoint(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// BoundingBox returns a box that contains every point within radiusM meters of
+// lat, lng. It is only a cheap pre-filter for an index, the exact check is
+// still the haversine distance. ok is false when the box would cross a pole
+// or the antimeridian, callers should skip the pre-filter then.
+func BoundingBox(lat, lng, radiusM float64) (minLat, maxLat, minLng, maxLng float64, ok bool) {
+	dLat := degrees(radiusM / EarthRadiusM)
+	minLat, maxLat = lat-dLat, lat+dLat
+	if minLat < -90 || maxLat > 90 {
+		return 0, 0, 0, 0, false
+	}
+
+	dLng := degrees(radiusM / (EarthRadiusM * math.Cos(radians(math.Max(math.Abs(minLat), math.Abs(maxLat))))))
+	minLng, maxLng = lng-dLng, lng+dLng
+	if minLng < -180 || maxLng > 180 {
+		return 0, 0, 0, 0, false
+	}
+
+	return minLat, maxLat, minLng, maxLng, true
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,172 @@
+package geo
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// destination returns the point distanceM meters from lat, lng in the
+// direction of bearing, in degrees clockwise from north.
+func destination(lat, lng, bearing, distanceM float64) (float64, float64) {
+	d := distanceM / EarthRadiusM
+	lat1, lng1, b := radians(lat), radians(lng), radians(bearing)
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	return degrees(lat2), degrees(lng2)
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name              string
+		lat, lng, radiusM float64
+		ok                bool
+	}{
+		{"tashkent", 41.2995, 69.2401, 1000, true},
+		{"far north", 70, 25, 50000, true},
+		{"southern hemisphere", -33.86, 151.21, 25000, true},
+		{"on the equator", 0, 0, 10000, true},
+		{"zero radius", 41.2995, 69.2401, 0, true},
+		{"over the north pole", 89.99, 0, 5000, false},
+		{"over the south pole", -89.99, 0, 5000, false},
+		{"over the antimeridian", 0, 179.99, 5000, false},
+		{"over the antimeridian westwards", 0, -179.99, 5000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLat, maxLat, minLng, maxLng, ok := BoundingBox(tt.lat, tt.lng, tt.radiusM)
+			if ok != tt.ok {
+				t.Fatalf("BoundingBox ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if !(minLat <= tt.lat && tt.lat <= maxLat && minLng <= tt.lng && tt.lng <= maxLng) {
+				t.Fatalf("box %v..%v, %v..%v doesn't contain its center", minLat, maxLat, minLng, maxLng)
+			}
+
+			// every point on the circle must be inside, the box is a pre-filter
+			// and must never drop a match
+			const eps = 1e-9
+			for bearing := 0.0; bearing < 360; bearing += 5 {
+				lat, lng := destination(tt.lat, tt.lng, bearing, tt.radiusM)
+				if lat < minLat-eps || lat > maxLat+eps || lng < minLng-eps || lng > maxLng+eps {
+					t.Errorf("point %v, %v at bearing %v is outside the box %v..%v, %v..%v",
+						lat, lng, bearing, minLat, maxLat, minLng, maxLng)
+				}
+			}
+		})
+	}
+}
+
+func TestValidPoint(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		want     bool
+	}{
+		{41.3, 69.2, true},
+		{90, 180, true},
+		{-90, -180, true},
+		{90.1, 0, false},
+		{0, -180.1, false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidPoint(tt.lat, tt.lng); got != tt.want {
+			t.Errorf("ValidPoint(%v, %v) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+		}
+	}
+}
+
+func writeGazetteer(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "gazetteer.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGeocode(t *testing.T) {
+	g, err := LoadGazetteer(writeGazetteer(t, `# test places
+name,latitude,longitude,level
+Tashkent,41.2995,69.2401,city
+Samarkand,39.6542,66.9597,city
+Farg'ona,40.3842,71.7843,city
+Chilonzor,41.2756,69.2034,district
+Mirzo Ulugbek,41.3275,69.3364,district
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		address string
+		want    string // empty when nothing is found
+	}{
+		{"Amir Temur 1, Tashkent", "Tashkent"},
+		{"TASHKENT", "Tashkent"},
+		{"Chilonzor 5, Tashkent", "Chilonzor"},
+		{"Tashkent, Chilonzor district", "Chilonzor"},
+		{"mirzo   ulugbek, tashkent", "Mirzo Ulugbek"},
+		// the city mentioned last wins
+		{"Samarkand Darvoza, Tashkent", "Tashkent"},
+		{"Tashkent street, Samarkand", "Samarkand"},
+		// apostrophes are ignored
+		{"Mustaqillik 3, Fargona", "Farg'ona"},
+		{"Mustaqillik 3, Farg‘ona", "Farg'ona"},
+		// only whole words match
+		{"Tashkentskaya 4", ""},
+		{"Nowhere 12", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		place, ok := g.Geocode(tt.address)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("Geocode(%q) = %q, want no match", tt.address, place.Name)
+			}
+			continue
+		}
+		if !ok || place.Name != tt.want {
+			t.Errorf("Geocode(%q) = %q, %v, want %q", tt.address, place.Name, ok, tt.want)
+		}
+	}
+
+	var none *Gazetteer
+	if _, ok := none.Geocode("Tashkent"); ok {
+		t.Error("Geocode on a nil gazetteer found a place")
+	}
+}
+
+func TestLoadGazetteerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing field", "Tashkent,41.2995,69.2401\n"},
+		{"bad latitude", "Tashkent,north,69.2401,city\n"},
+		{"out of range", "Tashkent,91,69.2401,city\n"},
+		{"empty name", "'',41.2995,69.2401,city\n"},
+		{"unknown level", "Tashkent,41.2995,69.2401,country\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadGazetteer(writeGazetteer(t, tt.content)); err == nil {
+				t.Error("LoadGazetteer succeeded, want an error")
+			}
+		})
+	}
+
+	if _, err := LoadGazetteer(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("LoadGazetteer of a missing file succeeded, want an error")
+	}
+}
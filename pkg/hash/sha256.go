@@ -0,0 +1,14 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken returns the hex encoded SHA-256 digest of a high-entropy token.
+// Unlike passwords, random tokens don't need a slow hash, and a deterministic
+// digest lets us look the token up by its hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
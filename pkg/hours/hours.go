@@ -0,0 +1,146 @@
+// Package hours works out whether a business is open from its weekly opening
+// hours and date-specific overrides.
+//
+// An interval runs from Opens to Closes on the day it belongs to. When Closes
+// isn't after Opens the interval ends on the next day, so 22:00-02:00 is a
+// late night and 00:00-00:00 is open around the clock. The hours of a day are
+// its overrides when it has any, otherwise the weekly hours of its weekday.
+// The sql function business_is_open in the migrations follows the same rules.
+package hours
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	// the runtime image has no zoneinfo of its own
+	_ "time/tzdata"
+)
+
+// DateLayout is the layout of override dates.
+const DateLayout = "2006-01-02"
+
+// Interval is a time of day range, as durations since midnight.
+type Interval struct {
+	Opens  time.Duration
+	Closes time.Duration
+}
+
+// Schedule is everything needed to tell when a business is open. Times are
+// local to Location.
+type Schedule struct {
+	Location *time.Location
+	// Weekly holds the intervals of each weekday, indexed by time.Weekday.
+	Weekly [7][]Interval
+	// Overrides replaces the weekly hours of a date, keyed by DateLayout. An
+	// empty slice means closed all day.
+	Overrides map[string][]Interval
+}
+
+// lookahead is how far NextChange searches, a business that is closed for
+// longer than this has no next change.
+const lookahead = 14
+
+// ParseClock parses "15:04" into a duration since midnight. "24:00" is midnight
+// at the end of the day.
+func ParseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 0, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// FormatClock is the inverse of ParseClock.
+func FormatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// IsOpen reports whether the business is open at t.
+func (s Schedule) IsOpen(t time.Time) bool {
+	t = t.In(s.Location)
+	for _, p := range s.periods(t) {
+		if !t.Before(p.start) && t.Before(p.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextChange returns when the business next opens or closes after t. ok is
+// false when it doesn't change within the next two weeks, e.g. it is always
+// open or has no hours at all.
+func (s Schedule) NextChange(t time.Time) (next time.Time, ok bool) {
+	t = t.In(s.Location)
+	for _, p := range s.periods(t) {
+		if p.start.After(t) {
+			return p.start, true
+		}
+		if p.end.After(t) {
+			// open now, the period ends before the lookahead does
+			if p.end.Before(s.day(t, lookahead)) {
+				return p.end, true
+			}
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
+type period struct {
+	start, end time.Time
+}
+
+// periods returns the merged open periods from the day before t, whose late
+// intervals may still be running, until the lookahead.
+func (s Schedule) periods(t time.Time) []period {
+	var periods []period
+	for offset := -1; offset <= lookahead; offset++ {
+		day := s.day(t, offset)
+
+		intervals, ok := s.Overrides[day.Format(DateLayout)]
+		if !ok {
+			intervals = s.Weekly[day.Weekday()]
+		}
+
+		for _, interval := range intervals {
+			start := s.at(day, interval.Opens)
+			end := s.at(day, interval.Closes)
+			if !end.After(start) {
+				end = s.at(s.day(day, 1), interval.Closes)
+			}
+			periods = append(periods, period{start, end})
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].start.Before(periods[j].start) })
+
+	merged := periods[:0]
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.start.After(merged[n-1].end) {
+			if p.end.After(merged[n-1].end) {
+				merged[n-1].end = p.end
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+// day returns midnight of the day offset days after the one t falls on.
+func (s Schedule) day(t time.Time, offset int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, s.Location)
+}
+
+// at returns the wall clock time d after midnight of day, which differs from
+// day.Add(d) on days the clocks change.
+func (s Schedule) at(day time.Time, d time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(d/time.Hour), int(d%time.Hour/time.Minute), 0, 0, s.Location)
+}
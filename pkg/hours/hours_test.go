@@ -0,0 +1,248 @@
+package hours
+
+import (
+	"testing"
+	"time"
+)
+
+func clock(t *testing.T, s string) time.Duration {
+	t.Helper()
+
+	d, err := ParseClock(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func interval(t *testing.T, opens, closes string) Interval {
+	t.Helper()
+
+	return Interval{Opens: clock(t, opens), Closes: clock(t, closes)}
+}
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func parseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return at
+}
+
+// weekSchedule is open late on Fridays, around the clock on Sundays and in
+// office hours on Mondays. 2026-06-05 is a Friday.
+func weekSchedule(t *testing.T) Schedule {
+	s := Schedule{Location: location(t, "Asia/Tashkent")}
+	s.Weekly[time.Friday] = []Interval{interval(t, "22:00", "02:00")}
+	s.Weekly[time.Sunday] = []Interval{interval(t, "00:00", "00:00")}
+	s.Weekly[time.Monday] = []Interval{interval(t, "09:00", "17:00")}
+	s.Overrides = map[string][]Interval{
+		"2026-06-15": {},                              // closed
+		"2026-06-22": {interval(t, "12:00", "14:00")}, // short day
+	}
+	return s
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "00:00", want: 0},
+		{in: "09:30", want: 9*time.Hour + 30*time.Minute},
+		{in: "23:59", want: 23*time.Hour + 59*time.Minute},
+		{in: "24:00", want: 0},
+		{in: "24:01", err: true},
+		{in: "9", err: true},
+		{in: "", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseClock(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseClock(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if !tt.err && got != tt.want {
+			t.Errorf("ParseClock(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if !tt.err && tt.in != "24:00" && FormatClock(got) != tt.in {
+			t.Errorf("FormatClock(%v) = %q, want %q", got, FormatClock(got), tt.in)
+		}
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	s := weekSchedule(t)
+
+	tests := []struct {
+		name string
+		at   string
+		want bool
+	}{
+		{"before a late night", "2026-06-05T21:59:00+05:00", false},
+		{"late night opens", "2026-06-05T22:00:00+05:00", true},
+		{"late night after midnight", "2026-06-06T01:59:00+05:00", true},
+		{"late night closes", "2026-06-06T02:00:00+05:00", false},
+		{"saturday has no hours", "2026-06-06T12:00:00+05:00", false},
+		{"around the clock starts", "2026-06-07T00:00:00+05:00", true},
+		{"around the clock at noon", "2026-06-07T12:00:00+05:00", true},
+		{"around the clock ends at midnight", "2026-06-08T00:00:00+05:00", false},
+		{"office hours", "2026-06-08T10:00:00+05:00", true},
+		{"office hours close", "2026-06-08T17:00:00+05:00", false},
+		{"closed override", "2026-06-15T10:00:00+05:00", false},
+		{"override hours", "2026-06-22T13:00:00+05:00", true},
+		{"weekly hours replaced by override", "2026-06-22T10:00:00+05:00", false},
+		{"other time zone", "2026-06-08T05:00:00Z", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.IsOpen(parseTime(t, tt.at)); got != tt.want {
+				t.Errorf("IsOpen(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextChange(t *testing.T) {
+	s := weekSchedule(t)
+
+	tests := []struct {
+		name string
+		at   string
+		want string // empty when there is no next change
+	}{
+		{"opens tonight", "2026-06-05T20:00:00+05:00", "2026-06-05T22:00:00+05:00"},
+		{"late night closes tomorrow", "2026-06-05T23:00:00+05:00", "2026-06-06T02:00:00+05:00"},
+		{"opens at midnight", "2026-06-06T12:00:00+05:00", "2026-06-07T00:00:00+05:00"},
+		{"around the clock closes at midnight", "2026-06-07T12:00:00+05:00", "2026-06-08T00:00:00+05:00"},
+		{"closes in the evening", "2026-06-08T10:00:00+05:00", "2026-06-08T17:00:00+05:00"},
+		{"closed override is skipped", "2026-06-14T12:00:00+05:00", "2026-06-15T00:00:00+05:00"},
+		{"override hours", "2026-06-22T09:00:00+05:00", "2026-06-22T12:00:00+05:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := s.NextChange(parseTime(t, tt.at))
+			if tt.want == "" {
+				if ok {
+					t.Errorf("NextChange(%s) = %s, want none", tt.at, next.Format(time.RFC3339))
+				}
+				return
+			}
+			if !ok || !next.Equal(parseTime(t, tt.want)) {
+				t.Errorf("NextChange(%s) = %s, %v, want %s", tt.at, next.Format(time.RFC3339), ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextChangeLookahead(t *testing.T) {
+	loc := location(t, "Asia/Tashkent")
+	at := parseTime(t, "2026-06-09T12:00:00+05:00") // a Tuesday
+
+	mondays := Schedule{Location: loc}
+	mondays.Weekly[time.Monday] = []Interval{interval(t, "09:00", "17:00")}
+
+	alwaysOpen := Schedule{Location: loc}
+	for day := range alwaysOpen.Weekly {
+		alwaysOpen.Weekly[day] = []Interval{interval(t, "00:00", "00:00")}
+	}
+
+	tests := []struct {
+		name      string
+		schedule  Schedule
+		overrides map[string][]Interval
+		want      string
+	}{
+		{"next week", mondays, nil, "2026-06-15T09:00:00+05:00"},
+		{"in two weeks", mondays, map[string][]Interval{"2026-06-15": {}}, "2026-06-22T09:00:00+05:00"},
+		{"later than two weeks", mondays, map[string][]Interval{"2026-06-15": {}, "2026-06-22": {}}, ""},
+		{"no hours", Schedule{Location: loc}, nil, ""},
+		{"always open", alwaysOpen, nil, ""},
+		{"closes after being open for two weeks", alwaysOpen, map[string][]Interval{"2026-06-21": {}}, "2026-06-21T00:00:00+05:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schedule
+			s.Overrides = tt.overrides
+
+			next, ok := s.NextChange(at)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("NextChange = %s, want none", next.Format(time.RFC3339))
+				}
+				return
+			}
+			if !ok || !next.Equal(parseTime(t, tt.want)) {
+				t.Errorf("NextChange = %s, %v, want %s", next.Format(time.RFC3339), ok, tt.want)
+			}
+		})
+	}
+}
+
+// Opening hours are wall clock times, so a night that spans a clock change is
+// an hour shorter or longer than it looks.
+func TestDaylightSaving(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+
+	// the clocks go forward in the night after Saturday 2026-03-28
+	spring := Schedule{Location: berlin}
+	spring.Weekly[time.Saturday] = []Interval{interval(t, "22:00", "04:00")}
+
+	// and back in the night of Sunday 2026-10-25
+	autumn := Schedule{Location: berlin}
+	autumn.Weekly[time.Sunday] = []Interval{interval(t, "01:00", "05:00")}
+
+	open := []struct {
+		schedule Schedule
+		at       string
+		want     bool
+	}{
+		{spring, "2026-03-28T22:00:00+01:00", true},
+		{spring, "2026-03-29T01:59:00+01:00", true},
+		{spring, "2026-03-29T03:30:00+02:00", true},
+		{spring, "2026-03-29T04:00:00+02:00", false},
+		{autumn, "2026-10-25T00:59:00+02:00", false},
+		{autumn, "2026-10-25T02:30:00+02:00", true},
+		{autumn, "2026-10-25T02:30:00+01:00", true},
+		{autumn, "2026-10-25T04:59:00+01:00", true},
+		{autumn, "2026-10-25T05:00:00+01:00", false},
+	}
+	for _, tt := range open {
+		if got := tt.schedule.IsOpen(parseTime(t, tt.at)); got != tt.want {
+			t.Errorf("IsOpen(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	next := []struct {
+		schedule Schedule
+		at, want string
+	}{
+		// 22:00 CET to 04:00 CEST is five hours
+		{spring, "2026-03-28T23:00:00+01:00", "2026-03-29T04:00:00+02:00"},
+		// 01:00 CEST to 05:00 CET is five hours
+		{autumn, "2026-10-25T01:30:00+02:00", "2026-10-25T05:00:00+01:00"},
+	}
+	for _, tt := range next {
+		got, ok := tt.schedule.NextChange(parseTime(t, tt.at))
+		if !ok || !got.Equal(parseTime(t, tt.want)) {
+			t.Errorf("NextChange(%s) = %s, %v, want %s", tt.at, got.Format(time.RFC3339), ok, tt.want)
+		}
+	}
+}
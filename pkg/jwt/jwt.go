@@ -6,21 +6,23 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ErrTokenExpired is returned by ParseJWT when the token's exp claim is in the past.
+var ErrTokenExpired = jwt.ErrTokenExpired
+
 type JwtGenerateRequest struct {
 	Keys      map[string]interface{} `json:"keys"`
 	JwtKey    string
 	ExpiresAt int64 `json:"expires_at"`
 }
 
-func GenerateJWT(keys map[string]interface{}, jwtKey string) (string, error) {
+func GenerateJWT(keys map[string]interface{}, keySet *KeySet) (string, error) {
 	claims := jwt.MapClaims{}
 
 	for key, value := range keys {
 		claims[key] = value
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtKey))
+	tokenString, err := keySet.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -28,16 +30,9 @@ func GenerateJWT(keys map[string]interface{}, jwtKey string) (string, error) {
 	return tokenString, nil
 }
 
-func ParseJWT(tokenString string, jwtKey string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the algorithm
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-
-		// Return the secret key
-		return []byte(jwtKey), nil
-	})
+// ParseJWT verifies the token with the key named by its kid header.
+func ParseJWT(tokenString string, keySet *KeySet) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, keySet.keyFunc, jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, err
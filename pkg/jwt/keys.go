@@ -0,0 +1,249 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+)
+
+// Key is one signing key pair, or only the public half of a key that is
+// still accepted for verification, e.g. after it has been rotated out.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds every key tokens may be verified with and the one new tokens are signed with.
+//
+// Keys are read from a directory: <kid>.pem is a PKCS#8 (or PKCS#1 RSA) private
+// key and <kid>.pub.pem a PKIX public key. RSA keys sign with RS256 and Ed25519
+// keys with EdDSA. To rotate, add the new key, make it active, and keep the old
+// key's public half until the last token signed with it has expired.
+//
+// Tokens without a kid are HS256 tokens signed with the shared secret. Without
+// an active key the set signs and verifies those only. Once there is an active
+// key anyone holding the secret must stop being able to log in, so HS256 tokens
+// are rejected, or accepted until hs256Until to let the tokens issued before the
+// switch run out.
+type KeySet struct {
+	active     *Key
+	keys       map[string]*Key
+	secret     []byte
+	hs256Until time.Time
+}
+
+// LoadKeySet reads the keys in dir. dir may be empty when only the shared
+// secret is used. hs256Until is an RFC3339 time or empty.
+func LoadKeySet(dir, activeKid, secret, hs256Until string) (*KeySet, error) {
+	ks := &KeySet{
+		keys:   map[string]*Key{},
+		secret: []byte(secret),
+	}
+
+	if hs256Until != "" {
+		until, err := time.Parse(time.RFC3339, hs256Until)
+		if err != nil {
+			return nil, fmt.Errorf("jwt - LoadKeySet - hs256Until: %w", err)
+		}
+		ks.hs256Until = until
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("jwt - LoadKeySet - os.ReadDir: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), privateKeySuffix) {
+				continue
+			}
+
+			key, err := loadKey(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("jwt - LoadKeySet - %s: %w", entry.Name(), err)
+			}
+
+			// a private key wins over a separately stored public key with the same kid
+			if existing, ok := ks.keys[key.ID]; ok && existing.PrivateKey != nil {
+				continue
+			}
+			ks.keys[key.ID] = key
+		}
+	}
+
+	if activeKid != "" {
+		key, ok := ks.keys[activeKid]
+		if !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("jwt - LoadKeySet: no private key for active kid %q", activeKid)
+		}
+		ks.active = key
+	}
+
+	return ks, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	name := filepath.Base(path)
+	key := &Key{}
+
+	if strings.HasSuffix(name, publicKeySuffix) {
+		key.ID = strings.TrimSuffix(name, publicKeySuffix)
+		key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		key.ID = strings.TrimSuffix(name, privateKeySuffix)
+		key.PrivateKey, err = parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		key.PublicKey = key.PrivateKey.Public()
+	}
+
+	switch key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.PublicKey)
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+// Sign returns a token with the given claims, signed by the active key.
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+
+	return token.SignedString(ks.active.PrivateKey)
+}
+
+// keyFunc picks the verification key from the token's kid header.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if ks.active != nil && !time.Now().Before(ks.hs256Until) {
+			return nil, fmt.Errorf("HS256 tokens are no longer accepted")
+		}
+		return ks.secret, nil
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid: %s", kid)
+	}
+
+	// the algorithm comes from our key, never from the token
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public halves of all keys, ordered by kid.
+func (ks *KeySet) JWKS() JWKS {
+	response := JWKS{Keys: []JWK{}}
+
+	for _, key := range ks.keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		response.Keys = append(response.Keys, jwk)
+	}
+
+	sort.Slice(response.Keys, func(i, j int) bool {
+		return response.Keys[i].Kid < response.Keys[j].Kid
+	})
+
+	return response
+}
@@ -0,0 +1,354 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+var (
+	rsaKey, _          = rsa.GenerateKey(rand.Reader, 2048)
+	rotatedRsaKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _        = ed25519.GenerateKey(rand.Reader)
+	_, rotatedEdKey, _ = ed25519.GenerateKey(rand.Reader)
+)
+
+func writePEM(t *testing.T, dir, name, typ string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePrivateKey(t *testing.T, dir, kid string, key crypto.Signer) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid+privateKeySuffix, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key crypto.PublicKey) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid+publicKeySuffix, "PUBLIC KEY", der)
+}
+
+// keyDir returns a directory with the private keys rsa1 and ed1 and the
+// public halves of the rotated out keys rsa0 and ed0.
+func keyDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "rsa1", rsaKey)
+	writePrivateKey(t, dir, "ed1", edKey)
+	writePublicKey(t, dir, "rsa0", rotatedRsaKey.Public())
+	writePublicKey(t, dir, "ed0", rotatedEdKey.Public())
+	return dir
+}
+
+func loadKeySet(t *testing.T, dir, activeKid, hs256Until string) *KeySet {
+	t.Helper()
+
+	ks, err := LoadKeySet(dir, activeKid, testSecret, hs256Until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// signWith signs claims with the given method and key, setting kid when it
+// isn't empty, the way a token we didn't issue could be made.
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, testClaims())
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSignAndParse(t *testing.T) {
+	dir := keyDir(t)
+
+	tests := []struct {
+		name      string
+		activeKid string
+		alg       string
+	}{
+		{"rs256", "rsa1", "RS256"},
+		{"eddsa", "ed1", "EdDSA"},
+		{"hs256 without an active key", "", "HS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := loadKeySet(t, dir, tt.activeKid, "")
+
+			s, err := ks.Sign(testClaims())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(s, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Method.Alg() != tt.alg {
+				t.Errorf("alg = %s, want %s", token.Method.Alg(), tt.alg)
+			}
+			if kid, _ := token.Header["kid"].(string); kid != tt.activeKid {
+				t.Errorf("kid = %q, want %q", kid, tt.activeKid)
+			}
+
+			claims, err := ParseJWT(s, ks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims["sub"] != "user-1" {
+				t.Errorf("sub = %v, want user-1", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestParseRotatedKey(t *testing.T) {
+	ks := loadKeySet(t, keyDir(t), "rsa1", "")
+
+	tokens := map[string]string{
+		"rsa0": signWith(t, jwt.SigningMethodRS256, rotatedRsaKey, "rsa0"),
+		"ed0":  signWith(t, jwt.SigningMethodEdDSA, rotatedEdKey, "ed0"),
+		"ed1":  signWith(t, jwt.SigningMethodEdDSA, edKey, "ed1"),
+	}
+	for kid, s := range tokens {
+		if _, err := ParseJWT(s, ks); err != nil {
+			t.Errorf("token of %s was rejected: %v", kid, err)
+		}
+	}
+}
+
+func TestParseHS256(t *testing.T) {
+	dir := keyDir(t)
+	hs256 := signWith(t, jwt.SigningMethodHS256, []byte(testSecret), "")
+
+	tests := []struct {
+		name       string
+		activeKid  string
+		hs256Until string
+		ok         bool
+	}{
+		{"without an active key", "", "", true},
+		{"with an active key", "rsa1", "", false},
+		{"before the cutoff", "rsa1", time.Now().Add(time.Hour).Format(time.RFC3339), true},
+		{"after the cutoff", "rsa1", time.Now().Add(-time.Hour).Format(time.RFC3339), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWT(hs256, loadKeySet(t, dir, tt.activeKid, tt.hs256Until))
+			if (err == nil) != tt.ok {
+				t.Errorf("ParseJWT error = %v, want accepted %v", err, tt.ok)
+			}
+		})
+	}
+
+	other := signWith(t, jwt.SigningMethodHS256, []byte("other-secret"), "")
+	if _, err := ParseJWT(other, loadKeySet(t, dir, "", "")); err == nil {
+		t.Error("token signed with another secret was accepted")
+	}
+}
+
+func TestParseWrongAlgorithm(t *testing.T) {
+	ks := loadKeySet(t, keyDir(t), "rsa1", time.Now().Add(time.Hour).Format(time.RFC3339))
+
+	rsaPublic, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		// the public key is no secret, it must not work as an HMAC key
+		{"hs256 with the rsa public key", signWith(t, jwt.SigningMethodHS256, rsaPublic, "rsa1")},
+		{"hs256 with the secret and a kid", signWith(t, jwt.SigningMethodHS256, []byte(testSecret), "rsa1")},
+		{"rs256 for an eddsa kid", signWith(t, jwt.SigningMethodRS256, rsaKey, "ed1")},
+		{"eddsa for an rs256 kid", signWith(t, jwt.SigningMethodEdDSA, edKey, "rsa1")},
+		{"rs384 for an rs256 kid", signWith(t, jwt.SigningMethodRS384, rsaKey, "rsa1")},
+		{"rs256 without a kid", signWith(t, jwt.SigningMethodRS256, rsaKey, "")},
+		{"unknown kid", signWith(t, jwt.SigningMethodRS256, rsaKey, "rsa2")},
+		{"key of another kid", signWith(t, jwt.SigningMethodRS256, rotatedRsaKey, "rsa1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseJWT(tt.token, ks); err == nil {
+				t.Error("token was accepted")
+			}
+		})
+	}
+}
+
+func TestParseExpiration(t *testing.T) {
+	ks := loadKeySet(t, keyDir(t), "ed1", "")
+
+	expired, err := ks.Sign(jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParseJWT(expired, ks); err == nil {
+		t.Error("expired token was accepted")
+	}
+
+	noExp, err := ks.Sign(jwt.MapClaims{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParseJWT(noExp, ks); err == nil {
+		t.Error("token without exp was accepted")
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := keyDir(t)
+
+	// a PKCS#1 RSA key, a public key stored next to its private key and files
+	// that aren't keys
+	writePEM(t, dir, "pkcs1"+privateKeySuffix, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rotatedRsaKey))
+	writePublicKey(t, dir, "ed1", rotatedEdKey.Public())
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("keys"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "old"+privateKeySuffix), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	ks := loadKeySet(t, dir, "pkcs1", "")
+
+	if len(ks.keys) != 5 {
+		t.Errorf("loaded %d keys, want 5", len(ks.keys))
+	}
+	if ks.active == nil || ks.active.Method != jwt.SigningMethodRS256 {
+		t.Errorf("active key = %+v, want the RS256 key pkcs1", ks.active)
+	}
+
+	ed1 := ks.keys["ed1"]
+	if ed1 == nil || ed1.PrivateKey == nil || !edKey.Public().(ed25519.PublicKey).Equal(ed1.PublicKey) {
+		t.Error("private key ed1 was replaced by its stored public key")
+	}
+	if ks.keys["rsa0"].PrivateKey != nil {
+		t.Error("public key rsa0 has a private key")
+	}
+}
+
+func TestLoadKeySetErrors(t *testing.T) {
+	badPEM := t.TempDir()
+	if err := os.WriteFile(filepath.Join(badPEM, "bad"+privateKeySuffix), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	badKey := t.TempDir()
+	writePEM(t, badKey, "bad"+privateKeySuffix, "PRIVATE KEY", []byte("not der"))
+
+	tests := []struct {
+		name       string
+		dir        string
+		activeKid  string
+		hs256Until string
+	}{
+		{"missing dir", filepath.Join(t.TempDir(), "missing"), "", ""},
+		{"no PEM data", badPEM, "", ""},
+		{"invalid key", badKey, "", ""},
+		{"unknown active kid", keyDir(t), "rsa2", ""},
+		{"active kid without a private key", keyDir(t), "rsa0", ""},
+		{"active kid without keys", "", "rsa1", ""},
+		{"invalid hs256 cutoff", "", "", "tomorrow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadKeySet(tt.dir, tt.activeKid, testSecret, tt.hs256Until); err == nil {
+				t.Error("LoadKeySet succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	jwks := loadKeySet(t, keyDir(t), "rsa1", "").JWKS()
+
+	var kids []string
+	for _, k := range jwks.Keys {
+		kids = append(kids, k.Kid)
+		if k.Use != "sig" {
+			t.Errorf("%s use = %q, want sig", k.Kid, k.Use)
+		}
+	}
+	if want := []string{"ed0", "ed1", "rsa0", "rsa1"}; !reflect.DeepEqual(kids, want) {
+		t.Fatalf("kids = %v, want %v", kids, want)
+	}
+
+	ed, rsa1 := jwks.Keys[1], jwks.Keys[3]
+
+	x, err := base64.RawURLEncoding.DecodeString(ed.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || !edKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Errorf("ed1 = %+v, want its Ed25519 public key", ed)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(rsa1.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(rsa1.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsa1.Kty != "RSA" || rsa1.Alg != "RS256" || rsa1.E != "AQAB" ||
+		new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaKey.E) {
+		t.Errorf("rsa1 = %+v, want its RSA public key", rsa1)
+	}
+
+	// the secret is never published
+	if empty := loadKeySet(t, "", "", "").JWKS(); empty.Keys == nil || len(empty.Keys) != 0 {
+		t.Errorf("JWKS without keys = %+v, want an empty list", empty)
+	}
+}
@@ -0,0 +1,114 @@
+// Package moderation pre-screens user written text before it is published.
+//
+// A screen never rejects anything by itself, it only returns the reasons a
+// person should look at the text first. Texts without flags can go live.
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// The reasons Screen flags a text for.
+const (
+	FlagBannedWord = "banned_word"
+	FlagLink       = "link"
+	FlagPhone      = "phone_number"
+	FlagDuplicate  = "duplicate"
+)
+
+var (
+	linkRegexp = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|io|ru|uz|info|biz|me|co)\b`)
+	// seven or more digits, optionally with a leading + and separators between them
+	phoneRegexp = regexp.MustCompile(`\+?\d(?:[\s().-]*\d){6,}`)
+)
+
+// duplicateSimilarity is the share of words two texts must have in common to
+// count as the same text, duplicateMinWords keeps short texts like "Great
+// place!" from matching each other.
+const (
+	duplicateSimilarity = 0.8
+	duplicateMinWords   = 5
+)
+
+// Screener holds the banned words a screen looks for.
+type Screener struct {
+	bannedWords map[string]bool
+}
+
+// New returns a Screener for the given banned words, case doesn't matter.
+func New(bannedWords []string) *Screener {
+	s := &Screener{bannedWords: make(map[string]bool, len(bannedWords))}
+	for _, word := range bannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			s.bannedWords[word] = true
+		}
+	}
+	return s
+}
+
+// Screen returns the flags of text, others are earlier texts of the same
+// author it must not repeat. No flags means the text can be published.
+func (s *Screener) Screen(text string, others []string) []string {
+	flags := []string{}
+	words := Words(text)
+
+	for _, word := range words {
+		if s.bannedWords[word] {
+			flags = append(flags, FlagBannedWord)
+			break
+		}
+	}
+
+	if linkRegexp.MatchString(text) {
+		flags = append(flags, FlagLink)
+	}
+
+	if phoneRegexp.MatchString(text) {
+		flags = append(flags, FlagPhone)
+	}
+
+	for _, other := range others {
+		if Similar(words, Words(other)) {
+			flags = append(flags, FlagDuplicate)
+			break
+		}
+	}
+
+	return flags
+}
+
+// Words splits text into lower case words without punctuation.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Similar reports whether two texts, given as their Words, are the same text
+// with a few words changed.
+func Similar(a, b []string) bool {
+	if len(a) < duplicateMinWords || len(b) < duplicateMinWords {
+		return false
+	}
+
+	setA := make(map[string]bool, len(a))
+	for _, word := range a {
+		setA[word] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, word := range b {
+		setB[word] = true
+	}
+
+	common := 0
+	for word := range setA {
+		if setB[word] {
+			common++
+		}
+	}
+
+	// jaccard index of the two sets of words
+	return float64(common)/float64(len(setA)+len(setB)-common) >= duplicateSimilarity
+}
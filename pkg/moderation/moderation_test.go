@@ -0,0 +1,89 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	s := New([]string{"Scam", "  idiot ", ""})
+
+	tests := []struct {
+		name   string
+		text   string
+		others []string
+		want   []string
+	}{
+		{"clean", "Great food and friendly staff, we will come back.", nil, []string{}},
+		{"banned word", "This place is a SCAM.", nil, []string{FlagBannedWord}},
+		{"banned word once", "scam scam, the owner is an idiot", nil, []string{FlagBannedWord}},
+		{"banned word only as a word", "The scampi were great.", nil, []string{}},
+		{"link", "Better food at https://example.org/menu", nil, []string{FlagLink}},
+		{"www link", "see www.example.uz", nil, []string{FlagLink}},
+		{"bare domain", "order at cheapfood.com instead", nil, []string{FlagLink}},
+		{"phone number", "Call me at +998 (90) 123-45-67", nil, []string{FlagPhone}},
+		{"phone number without separators", "call 901234567", nil, []string{FlagPhone}},
+		{"short numbers", "Table 12 for 4 people, paid 150000 in 2024", nil, []string{}},
+		{
+			"duplicate",
+			"The plov was cold and the waiter was rude to us",
+			[]string{"Nice view.", "the plov was cold and the waiter was very rude to us!"},
+			[]string{FlagDuplicate},
+		},
+		{
+			"different review of the same author",
+			"The plov was cold and the waiter was rude to us",
+			[]string{"Lovely terrace, the lagman was the best in town"},
+			[]string{},
+		},
+		{
+			"every flag",
+			"Scam! Call +998901234567 or visit scam.uz, the food here is awful",
+			[]string{"scam call +998901234567 or visit scam.uz the food here is awful"},
+			[]string{FlagBannedWord, FlagLink, FlagPhone, FlagDuplicate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Screen(tt.text, tt.others); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Screen(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Great PLOV, 10/10!! Ko'p yaxshi")
+	want := []string{"great", "plov", "10", "10", "ko", "p", "yaxshi"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words = %q, want %q", got, want)
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"same text", "the plov was cold and the waiter was rude", "The plov was cold, and the waiter was rude.", true},
+		{"one word added", "the plov was cold and the waiter was rude", "the plov was cold and the waiter was very rude", true},
+		{"word order ignored", "cold plov rude waiter long wait", "long wait rude waiter cold plov", true},
+		{"half the words changed", "the plov was cold and the waiter was rude", "the lagman was hot and the cook was kind", false},
+		{"short texts never match", "Great place!", "Great place!", false},
+		{"one short text", "great place to eat with family", "great place", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similar(Words(tt.a), Words(tt.b)); got != tt.want {
+				t.Errorf("Similar(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := Similar(Words(tt.b), Words(tt.a)); got != tt.want {
+				t.Errorf("Similar(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
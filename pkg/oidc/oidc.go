@@ -0,0 +1,388 @@
+// Package oidc is a small OpenID Connect relying party: discovery, the
+// authorization code flow with PKCE (S256) and id_token verification
+// against the provider's JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrNonceMismatch = errors.New("oidc: id_token nonce does not match")
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery is the part of /.well-known/openid-configuration the flow needs.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Claims are the identity claims read from a verified id_token.
+type Claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+}
+
+// Provider talks to a single OIDC provider. Discovery and keys are fetched
+// lazily and cached, so the app starts even if the provider is unreachable.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      map[string]crypto.PublicKey
+}
+
+func New(config Config) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Issuer returns the configured issuer, identities are stored per issuer.
+func (p *Provider) Issuer() string {
+	return p.config.Issuer
+}
+
+// RandomString returns a url safe random string for state, nonce and PKCE verifiers.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := &Discovery{}
+	err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", discovery)
+	if err != nil {
+		return nil, fmt.Errorf("oidc - Discover: %w", err)
+	}
+
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc - Discover: issuer mismatch, expected %q got %q", p.config.Issuer, discovery.Issuer)
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// AuthCodeURL returns the provider URL the user is sent to.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (Token, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("oidc - Exchange: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("oidc - Exchange: token endpoint returned %s", resp.Status)
+	}
+
+	token := Token{}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return Token{}, fmt.Errorf("oidc - Exchange: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return Token{}, errors.New("oidc - Exchange: no id_token in response")
+	}
+
+	return token, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an id_token.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("oidc - VerifyIDToken: %w", err)
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, errors.New("oidc - VerifyIDToken: invalid token")
+	}
+
+	claims := Claims{}
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.Nonce, _ = mapClaims["nonce"].(string)
+
+	// some providers send email_verified as a string
+	switch v := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, errors.New("oidc - VerifyIDToken: missing sub claim")
+	}
+
+	if claims.Nonce != nonce {
+		return Claims{}, ErrNonceMismatch
+	}
+
+	return claims, nil
+}
+
+// key returns the provider key with the given kid. Unknown kids trigger one
+// refetch of the JWKS, that is how providers announce rotated keys.
+func (p *Provider) key(ctx context.Context, discovery *Discovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx, discovery.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+func (p *Provider) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("fetching jwks: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			// skip key types we don't understand instead of failing the whole set
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
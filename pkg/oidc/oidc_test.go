@@ -0,0 +1,229 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider is a minimal OIDC provider: it issues one code per nonce and
+// checks the PKCE verifier when the code is exchanged.
+type mockProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	clientID string
+	audience string
+
+	challenge string
+	nonce     string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockProvider{key: key, kid: "mock-1", clientID: "yalp", audience: "yalp"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			JwksURI:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		pub := m.key.PublicKey
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": m.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" || CodeChallenge(r.Form.Get("code_verifier")) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(Token{
+			AccessToken: "access",
+			TokenType:   "Bearer",
+			IDToken:     m.idToken(t, jwt.MapClaims{}),
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockProvider) idToken(t *testing.T, override jwt.MapClaims) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            m.audience,
+		"sub":            "mock-subject",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "Mock User",
+		"nonce":          m.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range override {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func (m *mockProvider) provider() *Provider {
+	return New(Config{
+		Issuer:      m.server.URL,
+		ClientID:    m.clientID,
+		RedirectURL: "http://localhost/v1/auth/oidc/callback",
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	p := m.provider()
+
+	verifier, _ := RandomString()
+	state, _ := RandomString()
+	m.nonce, _ = RandomString()
+
+	authURL, err := p.AuthCodeURL(ctx, state, m.nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(authURL)
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("state") != state || query.Get("nonce") != m.nonce {
+		t.Fatalf("unexpected authorization url %s", authURL)
+	}
+	m.challenge = query.Get("code_challenge")
+
+	token, err := p.Exchange(ctx, "good-code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, m.nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "mock-subject" || claims.Email != "user@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	m.challenge = CodeChallenge("right")
+
+	_, err := m.provider().Exchange(context.Background(), "good-code", "wrong")
+	if err == nil {
+		t.Fatal("expected an error for a wrong code verifier")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	m.nonce = "nonce"
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": m.server.URL, "aud": m.clientID, "sub": "x", "nonce": "nonce",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	forged.Header["kid"] = m.kid
+	forgedToken, _ := forged.SignedString(other)
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"wrong nonce", m.idToken(t, nil), "other"},
+		{"wrong audience", m.idToken(t, jwt.MapClaims{"aud": "someone-else"}), "nonce"},
+		{"wrong issuer", m.idToken(t, jwt.MapClaims{"iss": "https://evil.example"}), "nonce"},
+		{"expired", m.idToken(t, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), "nonce"},
+		{"bad signature", forgedToken, "nonce"},
+	}
+
+	p := m.provider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyIDToken(ctx, tt.token, tt.nonce); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	_, err = p.VerifyIDToken(ctx, m.idToken(t, nil), "other")
+	if !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("expected ErrNonceMismatch, got %v", err)
+	}
+}
+
+func TestVerifyIDTokenAfterKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	p := m.provider()
+
+	if _, err := p.VerifyIDToken(ctx, m.idToken(t, nil), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.key, m.kid = key, "mock-2"
+
+	if _, err := p.VerifyIDToken(ctx, m.idToken(t, nil), ""); err != nil {
+		t.Fatalf("rotated key was not picked up: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package policywatcher tells every running instance that the casbin policy
+// changed. An instance that changes the policy writes a new version to a redis
+// key, the others poll the key and reload their enforcer when it moves.
+package policywatcher
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	rediscache "github.com/golanguzb70/redis-cache"
+)
+
+const versionKey = "casbin-policy-version"
+
+// Watcher implements persist.Watcher.
+type Watcher struct {
+	redis  rediscache.RedisCache
+	period time.Duration
+
+	mu       sync.Mutex
+	version  string
+	callback func(string)
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// New starts polling redis every period until Close is called.
+func New(redis rediscache.RedisCache, period time.Duration) *Watcher {
+	w := &Watcher{
+		redis:  redis,
+		period: period,
+		stop:   make(chan struct{}),
+	}
+
+	// the policy was just loaded, only versions written after this count
+	w.version, _ = redis.Get(context.Background(), versionKey)
+
+	go w.poll()
+	return w
+}
+
+// SetUpdateCallback sets the function called when another instance changed the policy.
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callback = callback
+	return nil
+}
+
+// Update publishes a new policy version. The caller has already applied the
+// change, so its own callback is not called.
+func (w *Watcher) Update() error {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.redis.Set(context.Background(), versionKey, version, 0); err != nil {
+		return err
+	}
+
+	w.version = version
+	return nil
+}
+
+// Close stops polling.
+func (w *Watcher) Close() {
+	w.once.Do(func() { close(w.stop) })
+}
+
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(w.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *Watcher) check() {
+	version, err := w.redis.Get(context.Background(), versionKey)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	if version == w.version {
+		w.mu.Unlock()
+		return
+	}
+	w.version = version
+	callback := w.callback
+	w.mu.Unlock()
+
+	if callback != nil {
+		callback(version)
+	}
+}
@@ -0,0 +1,147 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the secret of the test vectors in RFC 4226 and RFC 6238, the
+// ASCII string "12345678901234567890".
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func code(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	c, err := Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// RFC 4226 Appendix D, HOTP is TOTP with the counter as the step.
+func TestCodeHOTP(t *testing.T) {
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, w := range want {
+		if got := code(t, rfcSecret, int64(counter)); got != w {
+			t.Errorf("Code(counter %d) = %s, want %s", counter, got, w)
+		}
+	}
+}
+
+// RFC 6238 Appendix B, SHA1. The RFC uses 8 digits, the codes here are the
+// last 6 of them.
+func TestCodeTOTP(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0))
+		if got := code(t, rfcSecret, step); got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeSecret(t *testing.T) {
+	// secrets are typed in by hand, case and surrounding spaces don't matter
+	if got := code(t, " "+strings.ToLower(rfcSecret)+" ", 1); got != "287082" {
+		t.Errorf("Code with a lower case secret = %s, want 287082", got)
+	}
+
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code with an invalid secret succeeded, want an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	current := Step(at)
+
+	tests := []struct {
+		name string
+		code string
+		step int64
+		ok   bool
+	}{
+		{"current step", code(t, rfcSecret, current), current, true},
+		{"previous step", code(t, rfcSecret, current-1), current - 1, true},
+		{"next step", code(t, rfcSecret, current+1), current + 1, true},
+		{"two steps ago", code(t, rfcSecret, current-2), 0, false},
+		{"two steps ahead", code(t, rfcSecret, current+2), 0, false},
+		{"surrounding spaces", " " + code(t, rfcSecret, current) + "\n", current, true},
+		{"too short", code(t, rfcSecret, current)[:Digits-1], 0, false},
+		{"too long", code(t, rfcSecret, current) + "0", 0, false},
+		{"empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, at)
+			if ok != tt.ok || step != tt.step {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", "123456", at); ok {
+		t.Error("Validate with an invalid secret succeeded")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a == b {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+	if key, err := encoding.DecodeString(a); err != nil || len(key) != 20 {
+		t.Errorf("GenerateSecret = %q, want 20 bytes of base32", a)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	u, err := url.Parse(ProvisioningURI("Yalp", "user@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Yalp:user@example.com" {
+		t.Errorf("ProvisioningURI = %s, want otpauth://totp/Yalp:user@example.com", u)
+	}
+
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Yalp",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("ProvisioningURI %s = %q, want %q", k, got, v)
+		}
+	}
+}
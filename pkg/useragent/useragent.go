@@ -0,0 +1,97 @@
+// Package useragent extracts a human readable browser and operating system
+// from a User-Agent header. It only knows the common clients, anything else
+// is reported as "Unknown".
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+type UserAgent struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version"`
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// order matters: most browsers also claim to be Chrome and Safari
+var browsers = []rule{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"Yandex", regexp.MustCompile(`YaBrowser/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/([\d.]+)`)},
+	{"curl", regexp.MustCompile(`curl/([\d.]+)`)},
+	{"Mobile App", regexp.MustCompile(`(?:okhttp|Dart|Alamofire|CFNetwork)/([\d.]+)`)},
+}
+
+var systems = []rule{
+	{"iOS", regexp.MustCompile(`(?:iPhone|iPad|iPod).*? OS ([\d_]+)`)},
+	{"Android", regexp.MustCompile(`Android ([\d.]+)`)},
+	{"ChromeOS", regexp.MustCompile(`CrOS \S+ ([\d.]+)`)},
+	{"Windows", regexp.MustCompile(`Windows NT ([\d.]+)`)},
+	{"macOS", regexp.MustCompile(`Mac OS X ([\d_.]+)`)},
+	{"Linux", regexp.MustCompile(`Linux()`)},
+}
+
+// Parse returns the browser and operating system found in ua.
+func Parse(ua string) UserAgent {
+	result := UserAgent{
+		Browser: "Unknown",
+		OS:      "Unknown",
+	}
+
+	for _, r := range browsers {
+		if m := r.pattern.FindStringSubmatch(ua); m != nil {
+			result.Browser = r.name
+			result.BrowserVersion = major(m[1])
+			break
+		}
+	}
+
+	for _, r := range systems {
+		if m := r.pattern.FindStringSubmatch(ua); m != nil {
+			result.OS = r.name
+			result.OSVersion = strings.ReplaceAll(m[1], "_", ".")
+			break
+		}
+	}
+
+	if result.OS == "Windows" {
+		result.OSVersion = windowsVersion(result.OSVersion)
+	}
+
+	return result
+}
+
+func major(version string) string {
+	if i := strings.IndexByte(version, '.'); i > 0 {
+		return version[:i]
+	}
+	return version
+}
+
+// windowsVersion maps the NT kernel version to the marketing name.
+// Windows 11 still reports NT 10.0, so both are shown as 10.
+func windowsVersion(nt string) string {
+	switch nt {
+	case "10.0":
+		return "10"
+	case "6.3":
+		return "8.1"
+	case "6.2":
+		return "8"
+	case "6.1":
+		return "7"
+	}
+	return nt
+}